@@ -0,0 +1,1725 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// The gRPC API of the cosim demo app. The unary RPCs are executed as the commands of the
+// WebSocket protocol, and reply with the same feedback.
+//
+// The Go code is generated with protoc-gen-go v1.31.0 and protoc-gen-go-grpc v1.3.0:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//          --go-grpc_out=. --go-grpc_opt=paths=source_relative cosimapi/cosim.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: cosimapi/cosim.proto
+
+package cosimapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Empty struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{0}
+}
+
+type CommandReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Command string `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+}
+
+func (x *CommandReply) Reset() {
+	*x = CommandReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandReply) ProtoMessage() {}
+
+func (x *CommandReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandReply.ProtoReflect.Descriptor instead.
+func (*CommandReply) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CommandReply) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CommandReply) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CommandReply) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+type LoadRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ConfigPath string `protobuf:"bytes,1,opt,name=config_path,json=configPath,proto3" json:"config_path,omitempty"`
+	LogDir     string `protobuf:"bytes,2,opt,name=log_dir,json=logDir,proto3" json:"log_dir,omitempty"`
+}
+
+func (x *LoadRequest) Reset() {
+	*x = LoadRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadRequest) ProtoMessage() {}
+
+func (x *LoadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadRequest.ProtoReflect.Descriptor instead.
+func (*LoadRequest) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LoadRequest) GetConfigPath() string {
+	if x != nil {
+		return x.ConfigPath
+	}
+	return ""
+}
+
+func (x *LoadRequest) GetLogDir() string {
+	if x != nil {
+		return x.LogDir
+	}
+	return ""
+}
+
+type VariableRef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Module   string `protobuf:"bytes,1,opt,name=module,proto3" json:"module,omitempty"`
+	Variable string `protobuf:"bytes,2,opt,name=variable,proto3" json:"variable,omitempty"`
+}
+
+func (x *VariableRef) Reset() {
+	*x = VariableRef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VariableRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VariableRef) ProtoMessage() {}
+
+func (x *VariableRef) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VariableRef.ProtoReflect.Descriptor instead.
+func (*VariableRef) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *VariableRef) GetModule() string {
+	if x != nil {
+		return x.Module
+	}
+	return ""
+}
+
+func (x *VariableRef) GetVariable() string {
+	if x != nil {
+		return x.Variable
+	}
+	return ""
+}
+
+type SetValueRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Variable *VariableRef `protobuf:"bytes,1,opt,name=variable,proto3" json:"variable,omitempty"`
+	Value    string       `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *SetValueRequest) Reset() {
+	*x = SetValueRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetValueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetValueRequest) ProtoMessage() {}
+
+func (x *SetValueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetValueRequest.ProtoReflect.Descriptor instead.
+func (*SetValueRequest) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetValueRequest) GetVariable() *VariableRef {
+	if x != nil {
+		return x.Variable
+	}
+	return nil
+}
+
+func (x *SetValueRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type TrendSignalRef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Module string `protobuf:"bytes,1,opt,name=module,proto3" json:"module,omitempty"`
+	Signal string `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`
+	Axis   string `protobuf:"bytes,3,opt,name=axis,proto3" json:"axis,omitempty"`
+}
+
+func (x *TrendSignalRef) Reset() {
+	*x = TrendSignalRef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrendSignalRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrendSignalRef) ProtoMessage() {}
+
+func (x *TrendSignalRef) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrendSignalRef.ProtoReflect.Descriptor instead.
+func (*TrendSignalRef) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TrendSignalRef) GetModule() string {
+	if x != nil {
+		return x.Module
+	}
+	return ""
+}
+
+func (x *TrendSignalRef) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *TrendSignalRef) GetAxis() string {
+	if x != nil {
+		return x.Axis
+	}
+	return ""
+}
+
+type Trend struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       int32             `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PlotType string            `protobuf:"bytes,2,opt,name=plot_type,json=plotType,proto3" json:"plot_type,omitempty"`
+	Label    string            `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	Signals  []*TrendSignalRef `protobuf:"bytes,4,rep,name=signals,proto3" json:"signals,omitempty"`
+}
+
+func (x *Trend) Reset() {
+	*x = Trend{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Trend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trend) ProtoMessage() {}
+
+func (x *Trend) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trend.ProtoReflect.Descriptor instead.
+func (*Trend) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Trend) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Trend) GetPlotType() string {
+	if x != nil {
+		return x.PlotType
+	}
+	return ""
+}
+
+func (x *Trend) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Trend) GetSignals() []*TrendSignalRef {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+type TrendList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Trends []*Trend `protobuf:"bytes,1,rep,name=trends,proto3" json:"trends,omitempty"`
+}
+
+func (x *TrendList) Reset() {
+	*x = TrendList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrendList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrendList) ProtoMessage() {}
+
+func (x *TrendList) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrendList.ProtoReflect.Descriptor instead.
+func (*TrendList) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TrendList) GetTrends() []*Trend {
+	if x != nil {
+		return x.Trends
+	}
+	return nil
+}
+
+type NewTrendRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// trend or scatter.
+	PlotType string `protobuf:"bytes,1,opt,name=plot_type,json=plotType,proto3" json:"plot_type,omitempty"`
+	Label    string `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+}
+
+func (x *NewTrendRequest) Reset() {
+	*x = NewTrendRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewTrendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewTrendRequest) ProtoMessage() {}
+
+func (x *NewTrendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewTrendRequest.ProtoReflect.Descriptor instead.
+func (*NewTrendRequest) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *NewTrendRequest) GetPlotType() string {
+	if x != nil {
+		return x.PlotType
+	}
+	return ""
+}
+
+func (x *NewTrendRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type NewTrendReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reply *CommandReply `protobuf:"bytes,1,opt,name=reply,proto3" json:"reply,omitempty"`
+	Id    int32         `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *NewTrendReply) Reset() {
+	*x = NewTrendReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewTrendReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewTrendReply) ProtoMessage() {}
+
+func (x *NewTrendReply) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewTrendReply.ProtoReflect.Descriptor instead.
+func (*NewTrendReply) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *NewTrendReply) GetReply() *CommandReply {
+	if x != nil {
+		return x.Reply
+	}
+	return nil
+}
+
+func (x *NewTrendReply) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type TrendRef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *TrendRef) Reset() {
+	*x = TrendRef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrendRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrendRef) ProtoMessage() {}
+
+func (x *TrendRef) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrendRef.ProtoReflect.Descriptor instead.
+func (*TrendRef) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TrendRef) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type TrendSignalRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TrendId int32  `protobuf:"varint,1,opt,name=trend_id,json=trendId,proto3" json:"trend_id,omitempty"`
+	Module  string `protobuf:"bytes,2,opt,name=module,proto3" json:"module,omitempty"`
+	Signal  string `protobuf:"bytes,3,opt,name=signal,proto3" json:"signal,omitempty"`
+	// Only used when adding to a trend: x or y for scatter plots.
+	Axis string `protobuf:"bytes,4,opt,name=axis,proto3" json:"axis,omitempty"`
+}
+
+func (x *TrendSignalRequest) Reset() {
+	*x = TrendSignalRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrendSignalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrendSignalRequest) ProtoMessage() {}
+
+func (x *TrendSignalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrendSignalRequest.ProtoReflect.Descriptor instead.
+func (*TrendSignalRequest) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TrendSignalRequest) GetTrendId() int32 {
+	if x != nil {
+		return x.TrendId
+	}
+	return 0
+}
+
+func (x *TrendSignalRequest) GetModule() string {
+	if x != nil {
+		return x.Module
+	}
+	return ""
+}
+
+func (x *TrendSignalRequest) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *TrendSignalRequest) GetAxis() string {
+	if x != nil {
+		return x.Axis
+	}
+	return ""
+}
+
+type ScenarioRef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FileName string `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+}
+
+func (x *ScenarioRef) Reset() {
+	*x = ScenarioRef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScenarioRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScenarioRef) ProtoMessage() {}
+
+func (x *ScenarioRef) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScenarioRef.ProtoReflect.Descriptor instead.
+func (*ScenarioRef) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ScenarioRef) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+type ScenarioList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FileNames []string `protobuf:"bytes,1,rep,name=file_names,json=fileNames,proto3" json:"file_names,omitempty"`
+}
+
+func (x *ScenarioList) Reset() {
+	*x = ScenarioList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScenarioList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScenarioList) ProtoMessage() {}
+
+func (x *ScenarioList) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScenarioList.ProtoReflect.Descriptor instead.
+func (*ScenarioList) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ScenarioList) GetFileNames() []string {
+	if x != nil {
+		return x.FileNames
+	}
+	return nil
+}
+
+type StreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Update interval in milliseconds, the default interval when zero.
+	Interval int32 `protobuf:"varint,1,opt,name=interval,proto3" json:"interval,omitempty"`
+}
+
+func (x *StreamRequest) Reset() {
+	*x = StreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamRequest) ProtoMessage() {}
+
+func (x *StreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamRequest.ProtoReflect.Descriptor instead.
+func (*StreamRequest) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StreamRequest) GetInterval() int32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+type ExecutionStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Loaded                       bool    `protobuf:"varint,1,opt,name=loaded,proto3" json:"loaded,omitempty"`
+	State                        string  `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Time                         float64 `protobuf:"fixed64,3,opt,name=time,proto3" json:"time,omitempty"`
+	TotalAverageRealTimeFactor   float64 `protobuf:"fixed64,4,opt,name=total_average_real_time_factor,json=totalAverageRealTimeFactor,proto3" json:"total_average_real_time_factor,omitempty"`
+	RollingAverageRealTimeFactor float64 `protobuf:"fixed64,5,opt,name=rolling_average_real_time_factor,json=rollingAverageRealTimeFactor,proto3" json:"rolling_average_real_time_factor,omitempty"`
+	RealTimeFactorTarget         float64 `protobuf:"fixed64,6,opt,name=real_time_factor_target,json=realTimeFactorTarget,proto3" json:"real_time_factor_target,omitempty"`
+	RealTime                     bool    `protobuf:"varint,7,opt,name=real_time,json=realTime,proto3" json:"real_time,omitempty"`
+	RunningScenario              string  `protobuf:"bytes,8,opt,name=running_scenario,json=runningScenario,proto3" json:"running_scenario,omitempty"`
+	LastErrorCode                string  `protobuf:"bytes,9,opt,name=last_error_code,json=lastErrorCode,proto3" json:"last_error_code,omitempty"`
+	LastErrorMessage             string  `protobuf:"bytes,10,opt,name=last_error_message,json=lastErrorMessage,proto3" json:"last_error_message,omitempty"`
+}
+
+func (x *ExecutionStatus) Reset() {
+	*x = ExecutionStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExecutionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionStatus) ProtoMessage() {}
+
+func (x *ExecutionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionStatus.ProtoReflect.Descriptor instead.
+func (*ExecutionStatus) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExecutionStatus) GetLoaded() bool {
+	if x != nil {
+		return x.Loaded
+	}
+	return false
+}
+
+func (x *ExecutionStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ExecutionStatus) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *ExecutionStatus) GetTotalAverageRealTimeFactor() float64 {
+	if x != nil {
+		return x.TotalAverageRealTimeFactor
+	}
+	return 0
+}
+
+func (x *ExecutionStatus) GetRollingAverageRealTimeFactor() float64 {
+	if x != nil {
+		return x.RollingAverageRealTimeFactor
+	}
+	return 0
+}
+
+func (x *ExecutionStatus) GetRealTimeFactorTarget() float64 {
+	if x != nil {
+		return x.RealTimeFactorTarget
+	}
+	return 0
+}
+
+func (x *ExecutionStatus) GetRealTime() bool {
+	if x != nil {
+		return x.RealTime
+	}
+	return false
+}
+
+func (x *ExecutionStatus) GetRunningScenario() string {
+	if x != nil {
+		return x.RunningScenario
+	}
+	return ""
+}
+
+func (x *ExecutionStatus) GetLastErrorCode() string {
+	if x != nil {
+		return x.LastErrorCode
+	}
+	return ""
+}
+
+func (x *ExecutionStatus) GetLastErrorMessage() string {
+	if x != nil {
+		return x.LastErrorMessage
+	}
+	return ""
+}
+
+type SignalValuesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Variables []*VariableRef `protobuf:"bytes,1,rep,name=variables,proto3" json:"variables,omitempty"`
+	Interval  int32          `protobuf:"varint,2,opt,name=interval,proto3" json:"interval,omitempty"`
+}
+
+func (x *SignalValuesRequest) Reset() {
+	*x = SignalValuesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SignalValuesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalValuesRequest) ProtoMessage() {}
+
+func (x *SignalValuesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalValuesRequest.ProtoReflect.Descriptor instead.
+func (*SignalValuesRequest) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SignalValuesRequest) GetVariables() []*VariableRef {
+	if x != nil {
+		return x.Variables
+	}
+	return nil
+}
+
+func (x *SignalValuesRequest) GetInterval() int32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+type SignalValue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Module   string `protobuf:"bytes,1,opt,name=module,proto3" json:"module,omitempty"`
+	Variable string `protobuf:"bytes,2,opt,name=variable,proto3" json:"variable,omitempty"`
+	// Types that are assignable to Value:
+	//	*SignalValue_Real
+	//	*SignalValue_Integer
+	//	*SignalValue_Boolean
+	//	*SignalValue_String_
+	Value isSignalValue_Value `protobuf_oneof:"value"`
+}
+
+func (x *SignalValue) Reset() {
+	*x = SignalValue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SignalValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalValue) ProtoMessage() {}
+
+func (x *SignalValue) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalValue.ProtoReflect.Descriptor instead.
+func (*SignalValue) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SignalValue) GetModule() string {
+	if x != nil {
+		return x.Module
+	}
+	return ""
+}
+
+func (x *SignalValue) GetVariable() string {
+	if x != nil {
+		return x.Variable
+	}
+	return ""
+}
+
+func (m *SignalValue) GetValue() isSignalValue_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *SignalValue) GetReal() float64 {
+	if x, ok := x.GetValue().(*SignalValue_Real); ok {
+		return x.Real
+	}
+	return 0
+}
+
+func (x *SignalValue) GetInteger() int64 {
+	if x, ok := x.GetValue().(*SignalValue_Integer); ok {
+		return x.Integer
+	}
+	return 0
+}
+
+func (x *SignalValue) GetBoolean() bool {
+	if x, ok := x.GetValue().(*SignalValue_Boolean); ok {
+		return x.Boolean
+	}
+	return false
+}
+
+func (x *SignalValue) GetString_() string {
+	if x, ok := x.GetValue().(*SignalValue_String_); ok {
+		return x.String_
+	}
+	return ""
+}
+
+type isSignalValue_Value interface {
+	isSignalValue_Value()
+}
+
+type SignalValue_Real struct {
+	Real float64 `protobuf:"fixed64,3,opt,name=real,proto3,oneof"`
+}
+
+type SignalValue_Integer struct {
+	Integer int64 `protobuf:"varint,4,opt,name=integer,proto3,oneof"`
+}
+
+type SignalValue_Boolean struct {
+	Boolean bool `protobuf:"varint,5,opt,name=boolean,proto3,oneof"`
+}
+
+type SignalValue_String_ struct {
+	String_ string `protobuf:"bytes,6,opt,name=string,proto3,oneof"`
+}
+
+func (*SignalValue_Real) isSignalValue_Value() {}
+
+func (*SignalValue_Integer) isSignalValue_Value() {}
+
+func (*SignalValue_Boolean) isSignalValue_Value() {}
+
+func (*SignalValue_String_) isSignalValue_Value() {}
+
+type SignalValues struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Time   float64        `protobuf:"fixed64,1,opt,name=time,proto3" json:"time,omitempty"`
+	Values []*SignalValue `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *SignalValues) Reset() {
+	*x = SignalValues{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cosimapi_cosim_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SignalValues) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalValues) ProtoMessage() {}
+
+func (x *SignalValues) ProtoReflect() protoreflect.Message {
+	mi := &file_cosimapi_cosim_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalValues.ProtoReflect.Descriptor instead.
+func (*SignalValues) Descriptor() ([]byte, []int) {
+	return file_cosimapi_cosim_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SignalValues) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *SignalValues) GetValues() []*SignalValue {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+var File_cosimapi_cosim_proto protoreflect.FileDescriptor
+
+var file_cosimapi_cosim_proto_rawDesc = []byte{
+	0x0a, 0x14, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x73, 0x69, 0x6d,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x22, 0x07, 0x0a,
+	0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x5c, 0x0a, 0x0c, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x22, 0x47, 0x0a, 0x0b, 0x4c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x50, 0x61, 0x74, 0x68, 0x12, 0x17, 0x0a, 0x07, 0x6c, 0x6f, 0x67, 0x5f, 0x64, 0x69, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x67, 0x44, 0x69, 0x72, 0x22, 0x41, 0x0a,
+	0x0b, 0x56, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x12, 0x16, 0x0a, 0x06,
+	0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x6f,
+	0x64, 0x75, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65,
+	0x22, 0x57, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x56, 0x61,
+	0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x52, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61,
+	0x62, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x54, 0x0a, 0x0e, 0x54, 0x72, 0x65,
+	0x6e, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x66, 0x12, 0x16, 0x0a, 0x06, 0x6d,
+	0x6f, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x6f, 0x64,
+	0x75, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x61,
+	0x78, 0x69, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x78, 0x69, 0x73, 0x22,
+	0x7b, 0x0a, 0x05, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x6f, 0x74,
+	0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x6f,
+	0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x2f, 0x0a, 0x07, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x63,
+	0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c,
+	0x52, 0x65, 0x66, 0x52, 0x07, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x73, 0x22, 0x31, 0x0a, 0x09,
+	0x54, 0x72, 0x65, 0x6e, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x74, 0x72, 0x65,
+	0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x63, 0x6f, 0x73, 0x69,
+	0x6d, 0x2e, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x52, 0x06, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x73, 0x22,
+	0x44, 0x0a, 0x0f, 0x4e, 0x65, 0x77, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x6f, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x6f, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x22, 0x4a, 0x0a, 0x0d, 0x4e, 0x65, 0x77, 0x54, 0x72, 0x65, 0x6e,
+	0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x29, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x52, 0x05, 0x72, 0x65, 0x70, 0x6c,
+	0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69,
+	0x64, 0x22, 0x1a, 0x0a, 0x08, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x66, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x73, 0x0a,
+	0x12, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x16,
+	0x0a, 0x06, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x12, 0x12,
+	0x0a, 0x04, 0x61, 0x78, 0x69, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x78,
+	0x69, 0x73, 0x22, 0x2a, 0x0a, 0x0b, 0x53, 0x63, 0x65, 0x6e, 0x61, 0x72, 0x69, 0x6f, 0x52, 0x65,
+	0x66, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x2d,
+	0x0a, 0x0c, 0x53, 0x63, 0x65, 0x6e, 0x61, 0x72, 0x69, 0x6f, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x2b, 0x0a,
+	0x0d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x22, 0xb4, 0x03, 0x0a, 0x0f, 0x45,
+	0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65,
+	0x12, 0x42, 0x0a, 0x1e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67,
+	0x65, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x66, 0x61, 0x63, 0x74,
+	0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x1a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x41,
+	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x46, 0x61,
+	0x63, 0x74, 0x6f, 0x72, 0x12, 0x46, 0x0a, 0x20, 0x72, 0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x5f,
+	0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x5f, 0x66, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x1c,
+	0x72, 0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x41, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x65,
+	0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x46, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x35, 0x0a, 0x17,
+	0x72, 0x65, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x66, 0x61, 0x63, 0x74, 0x6f, 0x72,
+	0x5f, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x14, 0x72,
+	0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x46, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x54, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x29, 0x0a, 0x10, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x63, 0x65, 0x6e,
+	0x61, 0x72, 0x69, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x75, 0x6e, 0x6e,
+	0x69, 0x6e, 0x67, 0x53, 0x63, 0x65, 0x6e, 0x61, 0x72, 0x69, 0x6f, 0x12, 0x26, 0x0a, 0x0f, 0x6c,
+	0x61, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x43,
+	0x6f, 0x64, 0x65, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x10, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x22, 0x63, 0x0a, 0x13, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x09, 0x76, 0x61, 0x72, 0x69,
+	0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6f,
+	0x73, 0x69, 0x6d, 0x2e, 0x56, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x52,
+	0x09, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x22, 0xb2, 0x01, 0x0a, 0x0b, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x04, 0x72, 0x65,
+	0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x04, 0x72, 0x65, 0x61, 0x6c,
+	0x12, 0x1a, 0x0a, 0x07, 0x69, 0x6e, 0x74, 0x65, 0x67, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x48, 0x00, 0x52, 0x07, 0x69, 0x6e, 0x74, 0x65, 0x67, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x07,
+	0x62, 0x6f, 0x6f, 0x6c, 0x65, 0x61, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52,
+	0x07, 0x62, 0x6f, 0x6f, 0x6c, 0x65, 0x61, 0x6e, 0x12, 0x18, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x69,
+	0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69,
+	0x6e, 0x67, 0x42, 0x07, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x4e, 0x0a, 0x0c, 0x53,
+	0x69, 0x67, 0x6e, 0x61, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12,
+	0x2a, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x32, 0xff, 0x06, 0x0a, 0x05,
+	0x43, 0x6f, 0x73, 0x69, 0x6d, 0x12, 0x2f, 0x0a, 0x04, 0x4c, 0x6f, 0x61, 0x64, 0x12, 0x12, 0x2e,
+	0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2d, 0x0a, 0x08, 0x54, 0x65, 0x61, 0x72, 0x64, 0x6f,
+	0x77, 0x6e, 0x12, 0x0c, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x29, 0x0a, 0x04, 0x50, 0x6c, 0x61, 0x79, 0x12, 0x0c, 0x2e,
+	0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x63, 0x6f,
+	0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x2a, 0x0a, 0x05, 0x50, 0x61, 0x75, 0x73, 0x65, 0x12, 0x0c, 0x2e, 0x63, 0x6f, 0x73, 0x69,
+	0x6d, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x37, 0x0a, 0x08,
+	0x53, 0x65, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x16, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d,
+	0x2e, 0x53, 0x65, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x35, 0x0a, 0x0a, 0x52, 0x65, 0x73, 0x65, 0x74, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x12, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x56, 0x61, 0x72, 0x69,
+	0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2c, 0x0a, 0x0a,
+	0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x73, 0x12, 0x0c, 0x2e, 0x63, 0x6f, 0x73,
+	0x69, 0x6d, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x10, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d,
+	0x2e, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x38, 0x0a, 0x08, 0x4e, 0x65,
+	0x77, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x12, 0x16, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x4e,
+	0x65, 0x77, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14,
+	0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x4e, 0x65, 0x77, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x33, 0x0a, 0x0b, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x54, 0x72,
+	0x65, 0x6e, 0x64, 0x12, 0x0f, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x54, 0x72, 0x65, 0x6e,
+	0x64, 0x52, 0x65, 0x66, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x3c, 0x0a, 0x0a, 0x41, 0x64, 0x64,
+	0x54, 0x6f, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x12, 0x19, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e,
+	0x54, 0x72, 0x65, 0x6e, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x41, 0x0a, 0x0f, 0x52, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x46, 0x72, 0x6f, 0x6d, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x12, 0x19, 0x2e, 0x63, 0x6f, 0x73,
+	0x69, 0x6d, 0x2e, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x32, 0x0a, 0x0d, 0x4c, 0x69,
+	0x73, 0x74, 0x53, 0x63, 0x65, 0x6e, 0x61, 0x72, 0x69, 0x6f, 0x73, 0x12, 0x0c, 0x2e, 0x63, 0x6f,
+	0x73, 0x69, 0x6d, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69,
+	0x6d, 0x2e, 0x53, 0x63, 0x65, 0x6e, 0x61, 0x72, 0x69, 0x6f, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x37,
+	0x0a, 0x0c, 0x4c, 0x6f, 0x61, 0x64, 0x53, 0x63, 0x65, 0x6e, 0x61, 0x72, 0x69, 0x6f, 0x12, 0x12,
+	0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x53, 0x63, 0x65, 0x6e, 0x61, 0x72, 0x69, 0x6f, 0x52,
+	0x65, 0x66, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x32, 0x0a, 0x0d, 0x41, 0x62, 0x6f, 0x72, 0x74,
+	0x53, 0x63, 0x65, 0x6e, 0x61, 0x72, 0x69, 0x6f, 0x12, 0x0c, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x47, 0x0a, 0x15, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x63, 0x6f, 0x73,
+	0x69, 0x6d, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x30, 0x01, 0x12, 0x47, 0x0a, 0x12, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x63, 0x6f, 0x73,
+	0x69, 0x6d, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2e, 0x53,
+	0x69, 0x67, 0x6e, 0x61, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x30, 0x01, 0x42, 0x19, 0x5a,
+	0x17, 0x63, 0x6f, 0x73, 0x69, 0x6d, 0x2d, 0x64, 0x65, 0x6d, 0x6f, 0x2d, 0x61, 0x70, 0x70, 0x2f,
+	0x63, 0x6f, 0x73, 0x69, 0x6d, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_cosimapi_cosim_proto_rawDescOnce sync.Once
+	file_cosimapi_cosim_proto_rawDescData = file_cosimapi_cosim_proto_rawDesc
+)
+
+func file_cosimapi_cosim_proto_rawDescGZIP() []byte {
+	file_cosimapi_cosim_proto_rawDescOnce.Do(func() {
+		file_cosimapi_cosim_proto_rawDescData = protoimpl.X.CompressGZIP(file_cosimapi_cosim_proto_rawDescData)
+	})
+	return file_cosimapi_cosim_proto_rawDescData
+}
+
+var file_cosimapi_cosim_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_cosimapi_cosim_proto_goTypes = []interface{}{
+	(*Empty)(nil),               // 0: cosim.Empty
+	(*CommandReply)(nil),        // 1: cosim.CommandReply
+	(*LoadRequest)(nil),         // 2: cosim.LoadRequest
+	(*VariableRef)(nil),         // 3: cosim.VariableRef
+	(*SetValueRequest)(nil),     // 4: cosim.SetValueRequest
+	(*TrendSignalRef)(nil),      // 5: cosim.TrendSignalRef
+	(*Trend)(nil),               // 6: cosim.Trend
+	(*TrendList)(nil),           // 7: cosim.TrendList
+	(*NewTrendRequest)(nil),     // 8: cosim.NewTrendRequest
+	(*NewTrendReply)(nil),       // 9: cosim.NewTrendReply
+	(*TrendRef)(nil),            // 10: cosim.TrendRef
+	(*TrendSignalRequest)(nil),  // 11: cosim.TrendSignalRequest
+	(*ScenarioRef)(nil),         // 12: cosim.ScenarioRef
+	(*ScenarioList)(nil),        // 13: cosim.ScenarioList
+	(*StreamRequest)(nil),       // 14: cosim.StreamRequest
+	(*ExecutionStatus)(nil),     // 15: cosim.ExecutionStatus
+	(*SignalValuesRequest)(nil), // 16: cosim.SignalValuesRequest
+	(*SignalValue)(nil),         // 17: cosim.SignalValue
+	(*SignalValues)(nil),        // 18: cosim.SignalValues
+}
+var file_cosimapi_cosim_proto_depIdxs = []int32{
+	3,  // 0: cosim.SetValueRequest.variable:type_name -> cosim.VariableRef
+	5,  // 1: cosim.Trend.signals:type_name -> cosim.TrendSignalRef
+	6,  // 2: cosim.TrendList.trends:type_name -> cosim.Trend
+	1,  // 3: cosim.NewTrendReply.reply:type_name -> cosim.CommandReply
+	3,  // 4: cosim.SignalValuesRequest.variables:type_name -> cosim.VariableRef
+	17, // 5: cosim.SignalValues.values:type_name -> cosim.SignalValue
+	2,  // 6: cosim.Cosim.Load:input_type -> cosim.LoadRequest
+	0,  // 7: cosim.Cosim.Teardown:input_type -> cosim.Empty
+	0,  // 8: cosim.Cosim.Play:input_type -> cosim.Empty
+	0,  // 9: cosim.Cosim.Pause:input_type -> cosim.Empty
+	4,  // 10: cosim.Cosim.SetValue:input_type -> cosim.SetValueRequest
+	3,  // 11: cosim.Cosim.ResetValue:input_type -> cosim.VariableRef
+	0,  // 12: cosim.Cosim.ListTrends:input_type -> cosim.Empty
+	8,  // 13: cosim.Cosim.NewTrend:input_type -> cosim.NewTrendRequest
+	10, // 14: cosim.Cosim.RemoveTrend:input_type -> cosim.TrendRef
+	11, // 15: cosim.Cosim.AddToTrend:input_type -> cosim.TrendSignalRequest
+	11, // 16: cosim.Cosim.RemoveFromTrend:input_type -> cosim.TrendSignalRequest
+	0,  // 17: cosim.Cosim.ListScenarios:input_type -> cosim.Empty
+	12, // 18: cosim.Cosim.LoadScenario:input_type -> cosim.ScenarioRef
+	0,  // 19: cosim.Cosim.AbortScenario:input_type -> cosim.Empty
+	14, // 20: cosim.Cosim.StreamExecutionStatus:input_type -> cosim.StreamRequest
+	16, // 21: cosim.Cosim.StreamSignalValues:input_type -> cosim.SignalValuesRequest
+	1,  // 22: cosim.Cosim.Load:output_type -> cosim.CommandReply
+	1,  // 23: cosim.Cosim.Teardown:output_type -> cosim.CommandReply
+	1,  // 24: cosim.Cosim.Play:output_type -> cosim.CommandReply
+	1,  // 25: cosim.Cosim.Pause:output_type -> cosim.CommandReply
+	1,  // 26: cosim.Cosim.SetValue:output_type -> cosim.CommandReply
+	1,  // 27: cosim.Cosim.ResetValue:output_type -> cosim.CommandReply
+	7,  // 28: cosim.Cosim.ListTrends:output_type -> cosim.TrendList
+	9,  // 29: cosim.Cosim.NewTrend:output_type -> cosim.NewTrendReply
+	1,  // 30: cosim.Cosim.RemoveTrend:output_type -> cosim.CommandReply
+	1,  // 31: cosim.Cosim.AddToTrend:output_type -> cosim.CommandReply
+	1,  // 32: cosim.Cosim.RemoveFromTrend:output_type -> cosim.CommandReply
+	13, // 33: cosim.Cosim.ListScenarios:output_type -> cosim.ScenarioList
+	1,  // 34: cosim.Cosim.LoadScenario:output_type -> cosim.CommandReply
+	1,  // 35: cosim.Cosim.AbortScenario:output_type -> cosim.CommandReply
+	15, // 36: cosim.Cosim.StreamExecutionStatus:output_type -> cosim.ExecutionStatus
+	18, // 37: cosim.Cosim.StreamSignalValues:output_type -> cosim.SignalValues
+	22, // [22:38] is the sub-list for method output_type
+	6,  // [6:22] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_cosimapi_cosim_proto_init() }
+func file_cosimapi_cosim_proto_init() {
+	if File_cosimapi_cosim_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_cosimapi_cosim_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Empty); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoadRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VariableRef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetValueRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrendSignalRef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Trend); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrendList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewTrendRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewTrendReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrendRef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrendSignalRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScenarioRef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScenarioList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExecutionStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignalValuesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignalValue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cosimapi_cosim_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignalValues); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_cosimapi_cosim_proto_msgTypes[17].OneofWrappers = []interface{}{
+		(*SignalValue_Real)(nil),
+		(*SignalValue_Integer)(nil),
+		(*SignalValue_Boolean)(nil),
+		(*SignalValue_String_)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_cosimapi_cosim_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cosimapi_cosim_proto_goTypes,
+		DependencyIndexes: file_cosimapi_cosim_proto_depIdxs,
+		MessageInfos:      file_cosimapi_cosim_proto_msgTypes,
+	}.Build()
+	File_cosimapi_cosim_proto = out.File
+	file_cosimapi_cosim_proto_rawDesc = nil
+	file_cosimapi_cosim_proto_goTypes = nil
+	file_cosimapi_cosim_proto_depIdxs = nil
+}
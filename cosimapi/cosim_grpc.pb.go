@@ -0,0 +1,735 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// The gRPC API of the cosim demo app. The unary RPCs are executed as the commands of the
+// WebSocket protocol, and reply with the same feedback.
+//
+// The Go code is generated with protoc-gen-go v1.31.0 and protoc-gen-go-grpc v1.3.0:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//          --go-grpc_out=. --go-grpc_opt=paths=source_relative cosimapi/cosim.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: cosimapi/cosim.proto
+
+package cosimapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Cosim_Load_FullMethodName                  = "/cosim.Cosim/Load"
+	Cosim_Teardown_FullMethodName              = "/cosim.Cosim/Teardown"
+	Cosim_Play_FullMethodName                  = "/cosim.Cosim/Play"
+	Cosim_Pause_FullMethodName                 = "/cosim.Cosim/Pause"
+	Cosim_SetValue_FullMethodName              = "/cosim.Cosim/SetValue"
+	Cosim_ResetValue_FullMethodName            = "/cosim.Cosim/ResetValue"
+	Cosim_ListTrends_FullMethodName            = "/cosim.Cosim/ListTrends"
+	Cosim_NewTrend_FullMethodName              = "/cosim.Cosim/NewTrend"
+	Cosim_RemoveTrend_FullMethodName           = "/cosim.Cosim/RemoveTrend"
+	Cosim_AddToTrend_FullMethodName            = "/cosim.Cosim/AddToTrend"
+	Cosim_RemoveFromTrend_FullMethodName       = "/cosim.Cosim/RemoveFromTrend"
+	Cosim_ListScenarios_FullMethodName         = "/cosim.Cosim/ListScenarios"
+	Cosim_LoadScenario_FullMethodName          = "/cosim.Cosim/LoadScenario"
+	Cosim_AbortScenario_FullMethodName         = "/cosim.Cosim/AbortScenario"
+	Cosim_StreamExecutionStatus_FullMethodName = "/cosim.Cosim/StreamExecutionStatus"
+	Cosim_StreamSignalValues_FullMethodName    = "/cosim.Cosim/StreamSignalValues"
+)
+
+// CosimClient is the client API for Cosim service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CosimClient interface {
+	Load(ctx context.Context, in *LoadRequest, opts ...grpc.CallOption) (*CommandReply, error)
+	Teardown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandReply, error)
+	Play(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandReply, error)
+	Pause(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandReply, error)
+	SetValue(ctx context.Context, in *SetValueRequest, opts ...grpc.CallOption) (*CommandReply, error)
+	ResetValue(ctx context.Context, in *VariableRef, opts ...grpc.CallOption) (*CommandReply, error)
+	ListTrends(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TrendList, error)
+	NewTrend(ctx context.Context, in *NewTrendRequest, opts ...grpc.CallOption) (*NewTrendReply, error)
+	RemoveTrend(ctx context.Context, in *TrendRef, opts ...grpc.CallOption) (*CommandReply, error)
+	AddToTrend(ctx context.Context, in *TrendSignalRequest, opts ...grpc.CallOption) (*CommandReply, error)
+	RemoveFromTrend(ctx context.Context, in *TrendSignalRequest, opts ...grpc.CallOption) (*CommandReply, error)
+	ListScenarios(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ScenarioList, error)
+	LoadScenario(ctx context.Context, in *ScenarioRef, opts ...grpc.CallOption) (*CommandReply, error)
+	AbortScenario(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandReply, error)
+	// Sends the execution status at the update interval, which is longer while the simulation is stopped.
+	StreamExecutionStatus(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (Cosim_StreamExecutionStatusClient, error)
+	// Sends the values of the variables at the update interval.
+	StreamSignalValues(ctx context.Context, in *SignalValuesRequest, opts ...grpc.CallOption) (Cosim_StreamSignalValuesClient, error)
+}
+
+type cosimClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCosimClient(cc grpc.ClientConnInterface) CosimClient {
+	return &cosimClient{cc}
+}
+
+func (c *cosimClient) Load(ctx context.Context, in *LoadRequest, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_Load_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) Teardown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_Teardown_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) Play(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_Play_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) Pause(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_Pause_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) SetValue(ctx context.Context, in *SetValueRequest, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_SetValue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) ResetValue(ctx context.Context, in *VariableRef, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_ResetValue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) ListTrends(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TrendList, error) {
+	out := new(TrendList)
+	err := c.cc.Invoke(ctx, Cosim_ListTrends_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) NewTrend(ctx context.Context, in *NewTrendRequest, opts ...grpc.CallOption) (*NewTrendReply, error) {
+	out := new(NewTrendReply)
+	err := c.cc.Invoke(ctx, Cosim_NewTrend_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) RemoveTrend(ctx context.Context, in *TrendRef, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_RemoveTrend_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) AddToTrend(ctx context.Context, in *TrendSignalRequest, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_AddToTrend_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) RemoveFromTrend(ctx context.Context, in *TrendSignalRequest, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_RemoveFromTrend_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) ListScenarios(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ScenarioList, error) {
+	out := new(ScenarioList)
+	err := c.cc.Invoke(ctx, Cosim_ListScenarios_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) LoadScenario(ctx context.Context, in *ScenarioRef, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_LoadScenario_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) AbortScenario(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, Cosim_AbortScenario_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cosimClient) StreamExecutionStatus(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (Cosim_StreamExecutionStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Cosim_ServiceDesc.Streams[0], Cosim_StreamExecutionStatus_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cosimStreamExecutionStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cosim_StreamExecutionStatusClient interface {
+	Recv() (*ExecutionStatus, error)
+	grpc.ClientStream
+}
+
+type cosimStreamExecutionStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *cosimStreamExecutionStatusClient) Recv() (*ExecutionStatus, error) {
+	m := new(ExecutionStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cosimClient) StreamSignalValues(ctx context.Context, in *SignalValuesRequest, opts ...grpc.CallOption) (Cosim_StreamSignalValuesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Cosim_ServiceDesc.Streams[1], Cosim_StreamSignalValues_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cosimStreamSignalValuesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cosim_StreamSignalValuesClient interface {
+	Recv() (*SignalValues, error)
+	grpc.ClientStream
+}
+
+type cosimStreamSignalValuesClient struct {
+	grpc.ClientStream
+}
+
+func (x *cosimStreamSignalValuesClient) Recv() (*SignalValues, error) {
+	m := new(SignalValues)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CosimServer is the server API for Cosim service.
+// All implementations must embed UnimplementedCosimServer
+// for forward compatibility
+type CosimServer interface {
+	Load(context.Context, *LoadRequest) (*CommandReply, error)
+	Teardown(context.Context, *Empty) (*CommandReply, error)
+	Play(context.Context, *Empty) (*CommandReply, error)
+	Pause(context.Context, *Empty) (*CommandReply, error)
+	SetValue(context.Context, *SetValueRequest) (*CommandReply, error)
+	ResetValue(context.Context, *VariableRef) (*CommandReply, error)
+	ListTrends(context.Context, *Empty) (*TrendList, error)
+	NewTrend(context.Context, *NewTrendRequest) (*NewTrendReply, error)
+	RemoveTrend(context.Context, *TrendRef) (*CommandReply, error)
+	AddToTrend(context.Context, *TrendSignalRequest) (*CommandReply, error)
+	RemoveFromTrend(context.Context, *TrendSignalRequest) (*CommandReply, error)
+	ListScenarios(context.Context, *Empty) (*ScenarioList, error)
+	LoadScenario(context.Context, *ScenarioRef) (*CommandReply, error)
+	AbortScenario(context.Context, *Empty) (*CommandReply, error)
+	// Sends the execution status at the update interval, which is longer while the simulation is stopped.
+	StreamExecutionStatus(*StreamRequest, Cosim_StreamExecutionStatusServer) error
+	// Sends the values of the variables at the update interval.
+	StreamSignalValues(*SignalValuesRequest, Cosim_StreamSignalValuesServer) error
+	mustEmbedUnimplementedCosimServer()
+}
+
+// UnimplementedCosimServer must be embedded to have forward compatible implementations.
+type UnimplementedCosimServer struct {
+}
+
+func (UnimplementedCosimServer) Load(context.Context, *LoadRequest) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Load not implemented")
+}
+func (UnimplementedCosimServer) Teardown(context.Context, *Empty) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Teardown not implemented")
+}
+func (UnimplementedCosimServer) Play(context.Context, *Empty) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Play not implemented")
+}
+func (UnimplementedCosimServer) Pause(context.Context, *Empty) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedCosimServer) SetValue(context.Context, *SetValueRequest) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetValue not implemented")
+}
+func (UnimplementedCosimServer) ResetValue(context.Context, *VariableRef) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetValue not implemented")
+}
+func (UnimplementedCosimServer) ListTrends(context.Context, *Empty) (*TrendList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrends not implemented")
+}
+func (UnimplementedCosimServer) NewTrend(context.Context, *NewTrendRequest) (*NewTrendReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewTrend not implemented")
+}
+func (UnimplementedCosimServer) RemoveTrend(context.Context, *TrendRef) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTrend not implemented")
+}
+func (UnimplementedCosimServer) AddToTrend(context.Context, *TrendSignalRequest) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddToTrend not implemented")
+}
+func (UnimplementedCosimServer) RemoveFromTrend(context.Context, *TrendSignalRequest) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveFromTrend not implemented")
+}
+func (UnimplementedCosimServer) ListScenarios(context.Context, *Empty) (*ScenarioList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScenarios not implemented")
+}
+func (UnimplementedCosimServer) LoadScenario(context.Context, *ScenarioRef) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoadScenario not implemented")
+}
+func (UnimplementedCosimServer) AbortScenario(context.Context, *Empty) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AbortScenario not implemented")
+}
+func (UnimplementedCosimServer) StreamExecutionStatus(*StreamRequest, Cosim_StreamExecutionStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamExecutionStatus not implemented")
+}
+func (UnimplementedCosimServer) StreamSignalValues(*SignalValuesRequest, Cosim_StreamSignalValuesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSignalValues not implemented")
+}
+func (UnimplementedCosimServer) mustEmbedUnimplementedCosimServer() {}
+
+// UnsafeCosimServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CosimServer will
+// result in compilation errors.
+type UnsafeCosimServer interface {
+	mustEmbedUnimplementedCosimServer()
+}
+
+func RegisterCosimServer(s grpc.ServiceRegistrar, srv CosimServer) {
+	s.RegisterService(&Cosim_ServiceDesc, srv)
+}
+
+func _Cosim_Load_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).Load(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_Load_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).Load(ctx, req.(*LoadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_Teardown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).Teardown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_Teardown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).Teardown(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_Play_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).Play(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_Play_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).Play(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).Pause(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_SetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).SetValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_SetValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).SetValue(ctx, req.(*SetValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_ResetValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VariableRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).ResetValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_ResetValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).ResetValue(ctx, req.(*VariableRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_ListTrends_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).ListTrends(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_ListTrends_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).ListTrends(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_NewTrend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewTrendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).NewTrend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_NewTrend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).NewTrend(ctx, req.(*NewTrendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_RemoveTrend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrendRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).RemoveTrend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_RemoveTrend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).RemoveTrend(ctx, req.(*TrendRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_AddToTrend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrendSignalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).AddToTrend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_AddToTrend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).AddToTrend(ctx, req.(*TrendSignalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_RemoveFromTrend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrendSignalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).RemoveFromTrend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_RemoveFromTrend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).RemoveFromTrend(ctx, req.(*TrendSignalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_ListScenarios_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).ListScenarios(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_ListScenarios_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).ListScenarios(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_LoadScenario_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScenarioRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).LoadScenario(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_LoadScenario_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).LoadScenario(ctx, req.(*ScenarioRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_AbortScenario_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CosimServer).AbortScenario(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cosim_AbortScenario_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CosimServer).AbortScenario(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cosim_StreamExecutionStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CosimServer).StreamExecutionStatus(m, &cosimStreamExecutionStatusServer{stream})
+}
+
+type Cosim_StreamExecutionStatusServer interface {
+	Send(*ExecutionStatus) error
+	grpc.ServerStream
+}
+
+type cosimStreamExecutionStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *cosimStreamExecutionStatusServer) Send(m *ExecutionStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Cosim_StreamSignalValues_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SignalValuesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CosimServer).StreamSignalValues(m, &cosimStreamSignalValuesServer{stream})
+}
+
+type Cosim_StreamSignalValuesServer interface {
+	Send(*SignalValues) error
+	grpc.ServerStream
+}
+
+type cosimStreamSignalValuesServer struct {
+	grpc.ServerStream
+}
+
+func (x *cosimStreamSignalValuesServer) Send(m *SignalValues) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Cosim_ServiceDesc is the grpc.ServiceDesc for Cosim service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cosim_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cosim.Cosim",
+	HandlerType: (*CosimServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Load",
+			Handler:    _Cosim_Load_Handler,
+		},
+		{
+			MethodName: "Teardown",
+			Handler:    _Cosim_Teardown_Handler,
+		},
+		{
+			MethodName: "Play",
+			Handler:    _Cosim_Play_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _Cosim_Pause_Handler,
+		},
+		{
+			MethodName: "SetValue",
+			Handler:    _Cosim_SetValue_Handler,
+		},
+		{
+			MethodName: "ResetValue",
+			Handler:    _Cosim_ResetValue_Handler,
+		},
+		{
+			MethodName: "ListTrends",
+			Handler:    _Cosim_ListTrends_Handler,
+		},
+		{
+			MethodName: "NewTrend",
+			Handler:    _Cosim_NewTrend_Handler,
+		},
+		{
+			MethodName: "RemoveTrend",
+			Handler:    _Cosim_RemoveTrend_Handler,
+		},
+		{
+			MethodName: "AddToTrend",
+			Handler:    _Cosim_AddToTrend_Handler,
+		},
+		{
+			MethodName: "RemoveFromTrend",
+			Handler:    _Cosim_RemoveFromTrend_Handler,
+		},
+		{
+			MethodName: "ListScenarios",
+			Handler:    _Cosim_ListScenarios_Handler,
+		},
+		{
+			MethodName: "LoadScenario",
+			Handler:    _Cosim_LoadScenario_Handler,
+		},
+		{
+			MethodName: "AbortScenario",
+			Handler:    _Cosim_AbortScenario_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamExecutionStatus",
+			Handler:       _Cosim_StreamExecutionStatus_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamSignalValues",
+			Handler:       _Cosim_StreamSignalValues_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cosimapi/cosim.proto",
+}
@@ -1,14 +1,33 @@
 module cosim-demo-app
 
-go 1.18
+go 1.22.0
+
+require (
+	github.com/eclipse/paho.mqtt.golang v1.4.3
+	github.com/gobuffalo/packr v1.30.1
+	github.com/gopcua/opcua v0.7.1
+	github.com/gorilla/mux v1.7.4
+	github.com/gorilla/websocket v1.5.0
+	github.com/mochi-mqtt/server/v2 v2.3.0
+	github.com/rs/zerolog v1.28.0
+	github.com/ugorji/go/codec v1.1.7
+	google.golang.org/grpc v1.56.3
+	google.golang.org/protobuf v1.31.0
+)
 
 require (
-    github.com/gobuffalo/packr v1.30.1
-    github.com/gorilla/mux v1.7.4
-    github.com/gorilla/websocket v1.4.2
-    github.com/ugorji/go/codec v1.1.7
 	github.com/gobuffalo/envy v1.7.0 // indirect
 	github.com/gobuffalo/packd v0.3.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/joho/godotenv v1.3.0 // indirect
+	github.com/mattn/go-colorable v0.1.12 // indirect
+	github.com/mattn/go-isatty v0.0.14 // indirect
 	github.com/rogpeppe/go-internal v1.3.0 // indirect
+	github.com/rs/xid v1.4.0 // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
 )
@@ -0,0 +1,348 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"bytes"
+	"cosim-demo-app/structs"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bookmarks mark a simulation time with a label. They are added by the user, or automatically
+// when scenarios are loaded or aborted, scenario events take place, variables are overridden and
+// the execution fails. libcosim has no alarms of its own, so execution errors are what is
+// bookmarked as alarms. When the simulation logs to a folder, the bookmarks are saved next to the
+// CSV files of the run, named after the time of loading like them, and read back when that run is
+// opened. Bookmark ids only go up while the application runs, so that a new bookmark never takes
+// the id of a removed one.
+
+const bookmarksFilePrefix = "bookmarks_"
+
+const (
+	bookmarkUser     = "user"
+	bookmarkScenario = "scenario"
+	bookmarkOverride = "override"
+	bookmarkError    = "error"
+)
+
+const abortedScenarioLabel = "Aborted scenario "
+
+// IsScenarioAbort tells whether a bookmark was added for aborting a scenario.
+func IsScenarioAbort(bookmark structs.Bookmark) bool {
+	return bookmark.Source == bookmarkScenario && strings.HasPrefix(bookmark.Label, abortedScenarioLabel)
+}
+
+// runBookmarksPath returns where the bookmarks of a run loaded at the given time are saved.
+func runBookmarksPath(logDir string, loaded time.Time) string {
+	if len(logDir) == 0 {
+		return ""
+	}
+	return filepath.Join(logDir, strCat(bookmarksFilePrefix, formatRunTimestamp(loaded), ".json"))
+}
+
+func saveBookmarks(sim *Simulation, status *structs.SimulationStatus) {
+	if len(sim.bookmarksPath) == 0 {
+		return
+	}
+	bookmarksJson, _ := json.Marshal(status.Bookmarks)
+	err := ioutil.WriteFile(sim.bookmarksPath, bookmarksJson, 0644)
+	if err != nil {
+		log.Println("Could not write bookmarks to file:", err)
+	}
+}
+
+// readRunBookmarks reads the bookmarks saved for a recorded run, if any. They are in the file with
+// the timestamp closest to the one of the run.
+func readRunBookmarks(dir string, run recordedRun) []structs.Bookmark {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil || len(run.id) == 0 {
+		return nil
+	}
+	var path string
+	closest := runFileWindow
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, bookmarksFilePrefix) || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		saved, err := parseRunTimestamp(strings.TrimSuffix(strings.TrimPrefix(name, bookmarksFilePrefix), ".json"))
+		if err != nil {
+			continue
+		}
+		distance := saved.Sub(run.started)
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance <= closest {
+			path, closest = filepath.Join(dir, name), distance
+		}
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	return readBookmarks(path)
+}
+
+// readBookmarks reads the bookmarks saved in a file.
+func readBookmarks(path string) []structs.Bookmark {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Println("Could not read bookmarks from file:", err)
+		return nil
+	}
+	var bookmarks []structs.Bookmark
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		log.Println("Could not parse", path, "contents:", err)
+		return nil
+	}
+	sort.SliceStable(bookmarks, func(i, j int) bool {
+		return bookmarks[i].Time < bookmarks[j].Time
+	})
+	return bookmarks
+}
+
+// addBookmarkAt inserts a bookmark, keeping the bookmarks sorted by time.
+func addBookmarkAt(sim *Simulation, status *structs.SimulationStatus, time float64, label string, source string) structs.Bookmark {
+	sim.lastBookmarkId++
+	bookmark := structs.Bookmark{
+		Id:     sim.lastBookmarkId,
+		Time:   time,
+		Label:  label,
+		Source: source,
+	}
+	idx := sort.Search(len(status.Bookmarks), func(i int) bool {
+		return status.Bookmarks[i].Time > time
+	})
+	status.Bookmarks = append(status.Bookmarks, structs.Bookmark{})
+	copy(status.Bookmarks[idx+1:], status.Bookmarks[idx:])
+	status.Bookmarks[idx] = bookmark
+	saveBookmarks(sim, status)
+	return bookmark
+}
+
+// autoBookmark bookmarks the current simulation time.
+func autoBookmark(sim *Simulation, status *structs.SimulationStatus, label string, source string) {
+	if !status.Loaded {
+		return
+	}
+	addBookmarkAt(sim, status, getExecutionStatus(sim.Execution).time, label, source)
+}
+
+// addBookmark bookmarks the given simulation time, or the current time if none is given.
+func addBookmark(sim *Simulation, status *structs.SimulationStatus, label string, args []string) (bool, string) {
+	if !status.Loaded {
+		return false, "No simulation is loaded"
+	}
+	if len(strings.TrimSpace(label)) == 0 {
+		return false, "Bookmark label can't be empty"
+	}
+	time := getExecutionStatus(sim.Execution).time
+	if len(args) > 0 && len(args[0]) > 0 {
+		var err error
+		if time, err = strconv.ParseFloat(args[0], 64); err != nil {
+			return false, strCat("Cannot parse bookmark time as double: ", args[0])
+		}
+	}
+	bookmark := addBookmarkAt(sim, status, time, label, bookmarkUser)
+	return true, strCat("Added bookmark ", bookmark.Label, " at ", strconv.FormatFloat(bookmark.Time, 'f', -1, 64), " s")
+}
+
+func findBookmark(status *structs.SimulationStatus, bookmarkId string) (int, error) {
+	id, err := strconv.Atoi(bookmarkId)
+	if err != nil {
+		return -1, errors.New(strCat("Cannot parse bookmark id as integer: ", bookmarkId))
+	}
+	for idx, bookmark := range status.Bookmarks {
+		if bookmark.Id == id {
+			return idx, nil
+		}
+	}
+	return -1, errors.New(strCat("Bookmark with id ", bookmarkId, " does not exist"))
+}
+
+func setBookmarkLabel(sim *Simulation, status *structs.SimulationStatus, bookmarkId string, label string) (bool, string) {
+	idx, err := findBookmark(status, bookmarkId)
+	if err != nil {
+		return false, err.Error()
+	}
+	status.Bookmarks[idx].Label = label
+	saveBookmarks(sim, status)
+	return true, strCat("Renamed bookmark to ", label)
+}
+
+func removeBookmark(sim *Simulation, status *structs.SimulationStatus, bookmarkId string) (bool, string) {
+	idx, err := findBookmark(status, bookmarkId)
+	if err != nil {
+		return false, err.Error()
+	}
+	status.Bookmarks = append(status.Bookmarks[:idx], status.Bookmarks[idx+1:]...)
+	saveBookmarks(sim, status)
+	return true, strCat("Removed bookmark ", bookmarkId)
+}
+
+// describeVariable names a variable given by slave index and value reference, for use in bookmark labels.
+func describeVariable(sim *Simulation, slaveIndex string, valueType string, valueReference string) string {
+	for _, fmu := range sim.MetaData.FMUs {
+		if strconv.Itoa(fmu.ExecutionIndex) != slaveIndex {
+			continue
+		}
+		for _, variable := range fmu.Variables {
+			if variable.Type == valueType && strconv.Itoa(variable.ValueReference) == valueReference {
+				return strCat(fmu.Name, ".", variable.Name)
+			}
+		}
+	}
+	return strCat("slave ", slaveIndex, " value reference ", valueReference)
+}
+
+// scenarioEvent is an event of the running scenario that has not taken place yet.
+type scenarioEvent struct {
+	time  float64
+	label string
+}
+
+type scenarioFile struct {
+	Defaults struct {
+		Model    string `json:"model"`
+		Variable string `json:"variable"`
+		Action   string `json:"action"`
+	} `json:"defaults"`
+	Events []struct {
+		Time     float64     `json:"time"`
+		Model    string      `json:"model"`
+		Variable string      `json:"variable"`
+		Action   string      `json:"action"`
+		Value    interface{} `json:"value"`
+	} `json:"events"`
+}
+
+// scheduleScenarioEvents reads the events of a scenario that starts now, to bookmark each of them
+// when it takes place.
+func scheduleScenarioEvents(sim *Simulation, status *structs.SimulationStatus, filename string) {
+	sim.scenarioEvents = nil
+	data, err := ioutil.ReadFile(filepath.Join(status.ConfigDir, "scenarios", filename))
+	if err != nil {
+		log.Println("Could not read scenario events:", err)
+		return
+	}
+	var scenario scenarioFile
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		log.Println("Could not parse scenario events:", err)
+		return
+	}
+	start := getExecutionStatus(sim.Execution).time
+	for _, event := range scenario.Events {
+		model, variable, action := event.Model, event.Variable, event.Action
+		if len(model) == 0 {
+			model = scenario.Defaults.Model
+		}
+		if len(variable) == 0 {
+			variable = scenario.Defaults.Variable
+		}
+		if len(action) == 0 {
+			action = scenario.Defaults.Action
+		}
+		label := strCat("Scenario event: ", action, " ", model, ".", variable)
+		if event.Value != nil {
+			label = strCat(label, " ", fmt.Sprint(event.Value))
+		}
+		sim.scenarioEvents = append(sim.scenarioEvents, scenarioEvent{time: start + event.Time, label: label})
+	}
+	sort.SliceStable(sim.scenarioEvents, func(i, j int) bool {
+		return sim.scenarioEvents[i].time < sim.scenarioEvents[j].time
+	})
+}
+
+// checkScenarioEvents bookmarks the scenario events that have taken place since the last check.
+func checkScenarioEvents(sim *Simulation, status *structs.SimulationStatus) {
+	if len(sim.scenarioEvents) == 0 {
+		return
+	}
+	if !status.Loaded {
+		sim.scenarioEvents = nil
+		return
+	}
+	running := isScenarioRunning(sim.ScenarioManager)
+	now := getExecutionStatus(sim.Execution).time
+	for len(sim.scenarioEvents) > 0 && sim.scenarioEvents[0].time <= now {
+		addBookmarkAt(sim, status, sim.scenarioEvents[0].time, sim.scenarioEvents[0].label, bookmarkScenario)
+		sim.scenarioEvents = sim.scenarioEvents[1:]
+	}
+	if !running {
+		sim.scenarioEvents = nil
+	}
+}
+
+// checkExecutionError bookmarks the time at which the execution enters the error state.
+func checkExecutionError(sim *Simulation, status *structs.SimulationStatus) {
+	if !status.Loaded {
+		sim.executionFailed = false
+		return
+	}
+	execStatus := getExecutionStatus(sim.Execution)
+	failed := execStatus.state == "COSIM_EXECUTION_ERROR"
+	if failed && !sim.executionFailed {
+		addBookmarkAt(sim, status, execStatus.time, strCat("Execution error: ", execStatus.lastErrorMessage), bookmarkError)
+	}
+	sim.executionFailed = failed
+}
+
+// bookmarkLabels returns the labels of the bookmarks with after < time <= until, joined together.
+func bookmarkLabels(bookmarks []structs.Bookmark, after float64, until float64) string {
+	var labels []string
+	for _, bookmark := range bookmarks {
+		if bookmark.Time > after && bookmark.Time <= until {
+			labels = append(labels, bookmark.Label)
+		}
+	}
+	return strings.Join(labels, "; ")
+}
+
+// exportedBookmarks returns the bookmarks of the loaded simulation, or else of the open run.
+func exportedBookmarks(status *structs.SimulationStatus) []structs.Bookmark {
+	if !status.Loaded && status.Run != nil {
+		return status.Run.Bookmarks
+	}
+	return status.Bookmarks
+}
+
+// ExportBookmarks writes the bookmarks as CSV, built under the read lock.
+func ExportBookmarks(sim *Simulation, status *structs.SimulationStatus, writer io.Writer) error {
+	var buffer bytes.Buffer
+	sim.lock.RLock()
+	err := exportBookmarks(status, &buffer)
+	sim.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = buffer.WriteTo(writer)
+	return err
+}
+
+func exportBookmarks(status *structs.SimulationStatus, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write([]string{"Time", "Source", "Label"}); err != nil {
+		return err
+	}
+	for _, bookmark := range exportedBookmarks(status) {
+		record := []string{strconv.FormatFloat(bookmark.Time, 'g', -1, 64), bookmark.Source, bookmark.Label}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
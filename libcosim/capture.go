@@ -0,0 +1,324 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"bytes"
+	"cosim-demo-app/structs"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"strconv"
+)
+
+// A trend with an armed trigger watches one of its Real signals. When the signal crosses the
+// trigger level on the chosen edge, the samples of all the trend's Real signals from pre seconds
+// before to post seconds after the crossing are copied to a capture, and the trigger is disarmed.
+
+const maxCaptures = 20
+
+// triggerScan remembers the last sample of the trigger signal that has been checked for a crossing.
+type triggerScan struct {
+	lastTime  float64
+	lastValue float64
+}
+
+func findTriggerSignal(trend structs.Trend) (*structs.TrendSignal, error) {
+	for i, signal := range trend.TrendSignals {
+		if signal.Module == trend.Trigger.Module && signal.Signal == trend.Trigger.Signal {
+			return &trend.TrendSignals[i], nil
+		}
+	}
+	return nil, errors.New(strCat("Trigger signal ", trend.Trigger.Module, ".", trend.Trigger.Signal, " is not in trend ", trend.Label))
+}
+
+func setTrigger(sim *Simulation, status *structs.SimulationStatus, trendId string, module string, signal string, edge string, level string, pre string, post string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+	if edge != "rising" && edge != "falling" {
+		return false, strCat("Trigger edge must be rising or falling, got ", edge)
+	}
+	trigger := structs.Trigger{Module: module, Signal: signal, Edge: edge, Armed: true}
+	if trigger.Level, err = strconv.ParseFloat(level, 64); err != nil {
+		return false, strCat("Cannot parse trigger level as double: ", level)
+	}
+	if trigger.Pre, err = strconv.ParseFloat(pre, 64); err != nil || trigger.Pre < 0 {
+		return false, strCat("Pre-trigger duration must be a non-negative number: ", pre)
+	}
+	if trigger.Post, err = strconv.ParseFloat(post, 64); err != nil || trigger.Post < 0 {
+		return false, strCat("Post-trigger duration must be a non-negative number: ", post)
+	}
+
+	trend := &status.Trends[idx]
+	if trend.PlotType != "trend" {
+		return false, "Triggers can only be set on time series plots"
+	}
+	trend.Trigger = &trigger
+	triggerSignal, err := findTriggerSignal(*trend)
+	if err != nil || triggerSignal.Type != "Real" {
+		trend.Trigger = nil
+		return false, strCat("Trigger signal must be a Real signal in the plot: ", module, ".", signal)
+	}
+	delete(sim.triggerScans, trend.Id)
+	return true, strCat("Armed trigger on ", module, ".", signal, fullCapturesWarning(status))
+}
+
+// dropOrphanedTrigger removes the trigger of a trend when its signal has been removed from the trend.
+func dropOrphanedTrigger(sim *Simulation, trend *structs.Trend) bool {
+	if trend.Trigger == nil {
+		return false
+	}
+	if _, err := findTriggerSignal(*trend); err == nil {
+		return false
+	}
+	trend.Trigger = nil
+	delete(sim.triggerScans, trend.Id)
+	return true
+}
+
+// fullCapturesWarning tells that the oldest capture will be dropped when the trigger fires, if the
+// captures are full.
+func fullCapturesWarning(status *structs.SimulationStatus) string {
+	if len(status.Captures) < maxCaptures {
+		return ""
+	}
+	return strCat(". There are already ", strconv.Itoa(maxCaptures), " captures, capture ", strconv.Itoa(status.Captures[0].Id), " will be dropped when it fires")
+}
+
+func armTrigger(sim *Simulation, status *structs.SimulationStatus, trendId string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+	trigger := status.Trends[idx].Trigger
+	if trigger == nil {
+		return false, strCat("Plot ", status.Trends[idx].Label, " has no trigger")
+	}
+	trigger.Armed = true
+	trigger.Fired = false
+	delete(sim.triggerScans, status.Trends[idx].Id)
+	return true, strCat("Armed trigger on ", trigger.Module, ".", trigger.Signal, fullCapturesWarning(status))
+}
+
+func removeTrigger(sim *Simulation, status *structs.SimulationStatus, trendId string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+	status.Trends[idx].Trigger = nil
+	delete(sim.triggerScans, status.Trends[idx].Id)
+	return true, strCat("Removed trigger from plot ", status.Trends[idx].Label)
+}
+
+// crossing returns whether going from the previous value to the value crosses the level on the edge.
+func crossing(edge string, level float64, previous float64, value float64) bool {
+	if edge == "rising" {
+		return previous < level && value >= level
+	}
+	return previous > level && value <= level
+}
+
+// checkTriggers looks for trigger crossings among the samples added since the last check,
+// and captures the trends whose post-trigger duration has elapsed.
+func checkTriggers(sim *Simulation, status *structs.SimulationStatus) {
+	if !status.Loaded {
+		return
+	}
+	now := getExecutionStatus(sim.Execution).time
+	for idx := range status.Trends {
+		trend := &status.Trends[idx]
+		trigger := trend.Trigger
+		if trigger == nil || !trigger.Armed {
+			continue
+		}
+		if !trigger.Fired {
+			scanTrigger(sim, status, *trend, now)
+		}
+		if trigger.Fired && now >= trigger.FiredAt+trigger.Post {
+			captureTrend(sim, status, trend)
+		}
+	}
+}
+
+func scanTrigger(sim *Simulation, status *structs.SimulationStatus, trend structs.Trend, now float64) {
+	trigger := trend.Trigger
+	triggerSignal, err := findTriggerSignal(trend)
+	if err != nil {
+		return
+	}
+	if sim.triggerScans == nil {
+		sim.triggerScans = map[int]triggerScan{}
+	}
+	scan, scanning := sim.triggerScans[trend.Id]
+	if !scanning {
+		// Only crossings after the trigger was armed count.
+		scan = triggerScan{lastTime: now, lastValue: math.NaN()}
+	}
+
+	spec := structs.TrendSpec{Auto: false, Begin: scan.lastTime, End: now}
+	times, values := trendSignalRealSamples(sim, status, triggerSignal, spec)
+	for i, t := range times {
+		if t < scan.lastTime || (t == scan.lastTime && !math.IsNaN(scan.lastValue)) {
+			continue
+		}
+		if !math.IsNaN(scan.lastValue) && crossing(trigger.Edge, trigger.Level, scan.lastValue, values[i]) {
+			trigger.Fired = true
+			trigger.FiredAt = t
+			delete(sim.triggerScans, trend.Id)
+			return
+		}
+		scan = triggerScan{lastTime: t, lastValue: values[i]}
+	}
+	sim.triggerScans[trend.Id] = scan
+}
+
+func generateNextCaptureId(status *structs.SimulationStatus) int {
+	var maxId = 0
+	for _, capture := range status.Captures {
+		if capture.Id > maxId {
+			maxId = capture.Id
+		}
+	}
+	return maxId + 1
+}
+
+func captureTrend(sim *Simulation, status *structs.SimulationStatus, trend *structs.Trend) {
+	trigger := trend.Trigger
+	spec := structs.TrendSpec{Auto: false, Begin: trigger.FiredAt - trigger.Pre, End: trigger.FiredAt + trigger.Post}
+	capture := structs.Capture{
+		Id:      generateNextCaptureId(status),
+		TrendId: trend.Id,
+		Label:   trend.Label,
+		Trigger: *trigger,
+	}
+	for _, trendSignal := range trend.TrendSignals {
+		if trendSignal.Type != "Real" {
+			continue
+		}
+		signal := trendSignal
+		signal.TrendXValues, signal.TrendYValues = trendSignalRealSamples(sim, status, &signal, spec)
+		signal.TrendYValues = scaleValues(signal.TrendYValues, signal.Scale, signal.Offset)
+		capture.TrendSignals = append(capture.TrendSignals, signal)
+	}
+
+	status.Captures = append(status.Captures, capture)
+	if len(status.Captures) > maxCaptures {
+		dropped := status.Captures[:len(status.Captures)-maxCaptures]
+		for _, old := range dropped {
+			log.Println("Dropped capture", old.Id, "of plot", old.Label, "to make room for a new one")
+		}
+		status.Captures = status.Captures[len(dropped):]
+	}
+	trigger.Armed = false
+	trigger.Fired = false
+	log.Println("Captured plot", trend.Label, "triggered at", trigger.FiredAt)
+}
+
+func findCapture(status *structs.SimulationStatus, captureId string) (int, error) {
+	id, err := strconv.Atoi(captureId)
+	if err != nil {
+		return -1, errors.New(strCat("Cannot parse capture id as integer: ", captureId))
+	}
+	for idx, capture := range status.Captures {
+		if capture.Id == id {
+			return idx, nil
+		}
+	}
+	return -1, errors.New(strCat("Capture with id ", captureId, " does not exist"))
+}
+
+func viewCapture(status *structs.SimulationStatus, captureId string) (bool, string, *structs.Capture) {
+	idx, err := findCapture(status, captureId)
+	if err != nil {
+		return false, err.Error(), nil
+	}
+	capture := status.Captures[idx]
+	return true, strCat("Fetched capture ", captureId), &capture
+}
+
+func removeCapture(status *structs.SimulationStatus, captureId string) (bool, string) {
+	idx, err := findCapture(status, captureId)
+	if err != nil {
+		return false, err.Error()
+	}
+	status.Captures = append(status.Captures[:idx], status.Captures[idx+1:]...)
+	return true, strCat("Removed capture ", captureId)
+}
+
+// captureSummaries returns the captures without their samples, which are fetched one capture at a time.
+func captureSummaries(status *structs.SimulationStatus) []structs.Capture {
+	var summaries []structs.Capture
+	for _, capture := range status.Captures {
+		summary := capture
+		summary.TrendSignals = nil
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// ExportCaptures writes one or more captures as CSV for comparison. The first column is the time
+// relative to the trigger, followed by one column per signal and capture. All signals are resampled
+// onto the time points of the first signal of the first capture.
+func ExportCaptures(sim *Simulation, status *structs.SimulationStatus, captureIds []string, writer io.Writer) error {
+	var buffer bytes.Buffer
+	sim.lock.RLock()
+	err := exportCaptures(status, captureIds, &buffer)
+	sim.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = buffer.WriteTo(writer)
+	return err
+}
+
+func exportCaptures(status *structs.SimulationStatus, captureIds []string, writer io.Writer) error {
+	header := []string{"Time since trigger"}
+	var times []float64
+	var columns [][]float64
+	for _, captureId := range captureIds {
+		idx, err := findCapture(status, captureId)
+		if err != nil {
+			return err
+		}
+		capture := status.Captures[idx]
+		for _, signal := range capture.TrendSignals {
+			if len(signal.TrendXValues) == 0 {
+				continue
+			}
+			relativeTimes := make([]float64, len(signal.TrendXValues))
+			for i, t := range signal.TrendXValues {
+				relativeTimes[i] = t - capture.Trigger.FiredAt
+			}
+			if times == nil {
+				times = relativeTimes
+				columns = append(columns, signal.TrendYValues)
+			} else {
+				columns = append(columns, alignSamples(times, relativeTimes, signal.TrendYValues))
+			}
+			header = append(header, strCat("Capture ", strconv.Itoa(capture.Id), " ", signal.Module, ".", signal.Signal))
+		}
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	for i, t := range times {
+		record := make([]string, len(columns)+1)
+		record[0] = strconv.FormatFloat(t, 'g', -1, 64)
+		for j, column := range columns {
+			record[j+1] = strconv.FormatFloat(column[i], 'g', -1, 64)
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,214 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Every connected client has its own view, the signal subscriptions, the active trend and
+// the update interval, and gets responses tailored to it on its own state channel. Commands
+// without a client, like the ones posted to /command, use the view in SimulationStatus.
+// Commands don't send responses themselves, they mark the clients as due for an update,
+// and StateUpdateLoop sends the responses along with the feedback of the commands.
+
+const clientStateBuffer = 10
+
+// Update intervals in milliseconds. While the simulation isn't running, clients
+// are updated at most every stoppedUpdateInterval.
+const (
+	minUpdateInterval     = 50
+	maxUpdateInterval     = 5000
+	defaultUpdateInterval = 1000
+	stoppedUpdateInterval = 2000
+)
+
+type client struct {
+	state    chan structs.JsonResponse
+	view     structs.ClientView
+	lastSent time.Time
+	// sharesFeedback clients also get the feedback of commands issued by others.
+	sharesFeedback bool
+	// pending is set when a command changed the state, and the client is updated without
+	// waiting for its update interval, with the feedback and short lived data of the command.
+	pending  bool
+	feedback structs.CommandFeedback
+	own      bool
+	shorty   structs.ShortLivedData
+}
+
+// clientUpdate is what StateUpdateLoop needs to generate the response for a client.
+type clientUpdate struct {
+	view     structs.ClientView
+	feedback structs.CommandFeedback
+	shorty   structs.ShortLivedData
+}
+
+type Clients struct {
+	mutex   sync.Mutex
+	nextId  int
+	clients map[int]*client
+}
+
+func NewClients() *Clients {
+	return &Clients{clients: map[int]*client{}}
+}
+
+func NewClientView() structs.ClientView {
+	return structs.ClientView{ActiveTrend: -1, UpdateInterval: defaultUpdateInterval}
+}
+
+// resetView returns a fresh view for a newly loaded simulation, keeping the update interval.
+func resetView(view structs.ClientView) structs.ClientView {
+	fresh := NewClientView()
+	fresh.UpdateInterval = view.UpdateInterval
+	return fresh
+}
+
+func setUpdateInterval(view *structs.ClientView, interval string) (bool, string) {
+	milliseconds, err := strconv.Atoi(interval)
+	if err != nil {
+		return false, strCat("Cannot parse update interval as integer: ", interval)
+	}
+	if milliseconds < minUpdateInterval || milliseconds > maxUpdateInterval {
+		return false, strCat("Update interval must be between ", strconv.Itoa(minUpdateInterval), " and ", strconv.Itoa(maxUpdateInterval), " ms")
+	}
+	view.UpdateInterval = milliseconds
+	return true, strCat("Sending updates every ", interval, " ms")
+}
+
+// Register adds a client and returns its id and the channel its responses are sent on.
+func (clients *Clients) Register() (int, chan structs.JsonResponse) {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	clients.nextId++
+	state := make(chan structs.JsonResponse, clientStateBuffer)
+	clients.clients[clients.nextId] = &client{state: state, view: NewClientView()}
+	return clients.nextId, state
+}
+
+// Unregister removes a client and closes its state channel. Unregistering twice is harmless.
+func (clients *Clients) Unregister(id int) {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	if c, exists := clients.clients[id]; exists {
+		close(c.state)
+		delete(clients.clients, id)
+	}
+}
+
+// ShareFeedback makes a client get the feedback of the commands of all clients, not only its own.
+func (clients *Clients) ShareFeedback(id int) {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	if c, exists := clients.clients[id]; exists {
+		c.sharesFeedback = true
+	}
+}
+
+// mergeShortLived adds the short lived data of a command to the data not sent yet.
+func mergeShortLived(pending structs.ShortLivedData, shorty structs.ShortLivedData) structs.ShortLivedData {
+	if shorty.Scenarios != nil {
+		pending.Scenarios = shorty.Scenarios
+	}
+	if shorty.Scenario != nil {
+		pending.Scenario = shorty.Scenario
+	}
+	if shorty.ModuleData != nil {
+		pending.ModuleData = shorty.ModuleData
+	}
+	if shorty.PlotConfigReport != nil {
+		pending.PlotConfigReport = shorty.PlotConfigReport
+	}
+	if shorty.Capture != nil {
+		pending.Capture = shorty.Capture
+	}
+	return pending
+}
+
+// markDue marks all clients as due for an update after a command. The client that issued the
+// command gets its feedback and short lived data, the clients sharing feedback get the feedback
+// unless the feedback of a command of their own is still pending.
+func (clients *Clients) markDue(id int, feedback structs.CommandFeedback, shorty structs.ShortLivedData) {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	for clientId, c := range clients.clients {
+		c.pending = true
+		if clientId == id {
+			c.feedback = feedback
+			c.own = true
+			c.shorty = mergeShortLived(c.shorty, shorty)
+		} else if c.sharesFeedback && !c.own {
+			c.feedback = feedback
+		}
+	}
+}
+
+// due returns the updates of the clients marked due by a command, or whose update interval has
+// elapsed. Clients that haven't consumed their previous updates yet are skipped until they catch up.
+func (clients *Clients) due(now time.Time, running bool) map[int]clientUpdate {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	updates := map[int]clientUpdate{}
+	for id, c := range clients.clients {
+		interval := c.view.UpdateInterval
+		if !running && interval < stoppedUpdateInterval {
+			interval = stoppedUpdateInterval
+		}
+		if (!c.pending && now.Sub(c.lastSent) < time.Duration(interval)*time.Millisecond) || len(c.state) == cap(c.state) {
+			continue
+		}
+		updates[id] = clientUpdate{view: c.view, feedback: c.feedback, shorty: c.shorty}
+		c.pending = false
+		c.feedback = structs.CommandFeedback{}
+		c.own = false
+		c.shorty = structs.ShortLivedData{}
+	}
+	return updates
+}
+
+func (clients *Clients) view(id int) (structs.ClientView, bool) {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	c, exists := clients.clients[id]
+	if !exists {
+		return structs.ClientView{}, false
+	}
+	return c.view, true
+}
+
+func (clients *Clients) setView(id int, view structs.ClientView) {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	if c, exists := clients.clients[id]; exists {
+		c.view = view
+	}
+}
+
+func (clients *Clients) resetViews() {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	for _, c := range clients.clients {
+		c.view = resetView(c.view)
+	}
+}
+
+// send passes a response to a client, dropping it if the client doesn't keep up.
+func (clients *Clients) send(id int, response structs.JsonResponse) {
+	clients.mutex.Lock()
+	defer clients.mutex.Unlock()
+	c, exists := clients.clients[id]
+	if !exists {
+		return
+	}
+	select {
+	case c.state <- response:
+		c.lastSent = time.Now()
+	default:
+	}
+}
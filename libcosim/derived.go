@@ -0,0 +1,303 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"errors"
+	"log"
+	"strings"
+)
+
+const derivedModule = "Derived"
+const derivedCausality = "derived"
+
+var errNotFinite = errors.New("Derived signal value is not a finite number")
+
+type resolvedReference struct {
+	reference      variableReference
+	slaveIndex     int
+	valueReference int
+}
+
+// splitDerivedName splits a derived signal name like "Ship.power" into module and signal.
+// Names without a module part are put in the Derived module.
+func splitDerivedName(name string) (module string, signal string) {
+	separator := strings.Index(name, ".")
+	if separator <= 0 {
+		return derivedModule, name
+	}
+	return name[:separator], name[separator+1:]
+}
+
+func findDerivedSignal(status *structs.SimulationStatus, module string, signal string) (structs.DerivedSignal, error) {
+	for _, derived := range status.DerivedSignals {
+		if derived.Module == module && derived.Name == signal {
+			return derived, nil
+		}
+	}
+	return structs.DerivedSignal{}, errors.New("Derived signal " + module + "." + signal + " does not exist.")
+}
+
+func isDerivedModule(status *structs.SimulationStatus, module string) bool {
+	for _, derived := range status.DerivedSignals {
+		if derived.Module == module {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveReferences(metaData *structs.MetaData, expr expression) ([]resolvedReference, error) {
+	var resolved []resolvedReference
+	for _, reference := range expr.references {
+		fmu, err := findFmu(metaData, reference.Module)
+		if err != nil {
+			return nil, err
+		}
+		variable, err := findVariable(fmu, reference.Variable)
+		if err != nil {
+			return nil, err
+		}
+		if variable.Type != "Real" {
+			return nil, errors.New(strCat("Derived signals can only reference Real variables, ", reference.Module, ".", reference.Variable, " is ", variable.Type))
+		}
+		resolved = append(resolved, resolvedReference{
+			reference:      reference,
+			slaveIndex:     fmu.ExecutionIndex,
+			valueReference: variable.ValueReference,
+		})
+	}
+	return resolved, nil
+}
+
+func compileDerivedSignal(metaData *structs.MetaData, derived structs.DerivedSignal) (expression, []resolvedReference, error) {
+	expr, err := parseExpression(derived.Expression)
+	if err != nil {
+		return expr, nil, err
+	}
+	if len(expr.references) == 0 {
+		return expr, nil, errors.New(strCat("Expression for ", derived.Module, ".", derived.Name, " does not reference any variables"))
+	}
+	references, err := resolveReferences(metaData, expr)
+	return expr, references, err
+}
+
+type compiledDerivedSignal struct {
+	expr       expression
+	references []resolvedReference
+}
+
+// compiledDerived returns the compiled expression of a derived signal. The expressions are compiled
+// when the signals are added, in the command loop, so that readers holding the read lock only look
+// them up. They are kept until the simulation is torn down.
+func compiledDerived(sim *Simulation, derived structs.DerivedSignal) (expression, []resolvedReference, error) {
+	compiled, exists := sim.derivedSignals[derived]
+	if !exists {
+		return expression{}, nil, errors.New(strCat("Derived signal ", derived.Module, ".", derived.Name, " is not compiled"))
+	}
+	return compiled.expr, compiled.references, nil
+}
+
+func addDerivedSignal(sim *Simulation, status *structs.SimulationStatus, name string, expressionText string) (bool, string) {
+	if !status.Loaded {
+		return false, "No simulation is loaded"
+	}
+	module, signal := splitDerivedName(strings.TrimSpace(name))
+	if len(signal) == 0 {
+		return false, strCat("Invalid derived signal name: ", name)
+	}
+	if _, err := findFmu(sim.MetaData, module); err == nil {
+		return false, strCat("Derived signal module ", module, " clashes with a simulator of the same name")
+	}
+	if _, err := findDerivedSignal(status, module, signal); err == nil {
+		return false, strCat("Derived signal ", module, ".", signal, " already exists")
+	}
+
+	derived := structs.DerivedSignal{
+		Module:     module,
+		Name:       signal,
+		Expression: expressionText,
+	}
+	expr, references, err := compileDerivedSignal(sim.MetaData, derived)
+	if err != nil {
+		message := strCat("Invalid expression for derived signal ", module, ".", signal, ": ", err.Error())
+		log.Println(message)
+		return false, message
+	}
+
+	if sim.derivedSignals == nil {
+		sim.derivedSignals = map[structs.DerivedSignal]compiledDerivedSignal{}
+	}
+	sim.derivedSignals[derived] = compiledDerivedSignal{expr, references}
+	status.DerivedSignals = append(status.DerivedSignals, derived)
+	return true, strCat("Added derived signal ", module, ".", signal)
+}
+
+func removeDerivedSignal(sim *Simulation, status *structs.SimulationStatus, name string) (bool, string) {
+	module, signal := splitDerivedName(strings.TrimSpace(name))
+	for _, trend := range status.Trends {
+		for _, trendSignal := range trend.TrendSignals {
+			if trendSignal.Causality == derivedCausality && trendSignal.Module == module && trendSignal.Signal == signal {
+				return false, strCat("Derived signal ", module, ".", signal, " is used in plot ", trend.Label)
+			}
+		}
+	}
+	for i, derived := range status.DerivedSignals {
+		if derived.Module == module && derived.Name == signal {
+			status.DerivedSignals = append(status.DerivedSignals[:i], status.DerivedSignals[i+1:]...)
+			delete(sim.derivedSignals, derived)
+			return true, strCat("Removed derived signal ", module, ".", signal)
+		}
+	}
+	return false, strCat("Derived signal ", module, ".", signal, " does not exist")
+}
+
+// alignSamples resamples a series onto the given time points, holding the last known value.
+func alignSamples(baseTimes []float64, times []float64, values []float64) []float64 {
+	aligned := make([]float64, len(baseTimes))
+	cursor := 0
+	for i, t := range baseTimes {
+		for cursor+1 < len(times) && times[cursor+1] <= t {
+			cursor++
+		}
+		aligned[i] = values[cursor]
+	}
+	return aligned
+}
+
+func derivedRealSamples(sim *Simulation, status *structs.SimulationStatus, signal *structs.TrendSignal, spec structs.TrendSpec) (times []float64, values []float64) {
+	derived, err := findDerivedSignal(status, signal.Module, signal.Signal)
+	if err != nil {
+		return
+	}
+	expr, references, err := compiledDerived(sim, derived)
+	if err != nil {
+		return
+	}
+
+	series := make([][]float64, len(references))
+	for i, reference := range references {
+		refTimes, refValues := bufferedRealSamples(sim, reference.slaveIndex, reference.valueReference, spec)
+		if len(refTimes) == 0 {
+			return nil, nil
+		}
+		if i == 0 {
+			times = refTimes
+			series[i] = refValues
+		} else {
+			series[i] = alignSamples(times, refTimes, refValues)
+		}
+	}
+
+	// Samples where the expression has no finite value are left out.
+	baseTimes := times
+	times = make([]float64, 0, len(baseTimes))
+	values = make([]float64, 0, len(baseTimes))
+	env := make(map[variableReference]float64, len(references))
+	for t := range baseTimes {
+		for i, reference := range references {
+			env[reference.reference] = series[i][t]
+		}
+		if value, finite := expr.eval(env); finite {
+			times = append(times, baseTimes[t])
+			values = append(values, value)
+		}
+	}
+	return times, values
+}
+
+func derivedGetRealSamples(sim *Simulation, status *structs.SimulationStatus, signal *structs.TrendSignal, spec structs.TrendSpec) {
+	times, values := derivedRealSamples(sim, status, signal, spec)
+	if len(times) == 0 {
+		return
+	}
+	signal.TrendXValues = times
+	signal.TrendYValues = values
+}
+
+// trendSignalRealSamples returns the samples of any Real trend signal, derived, recorded or observed.
+func trendSignalRealSamples(sim *Simulation, status *structs.SimulationStatus, signal *structs.TrendSignal, spec structs.TrendSpec) ([]float64, []float64) {
+	switch signal.Causality {
+	case derivedCausality:
+		return derivedRealSamples(sim, status, signal, spec)
+	case recordedCausality:
+		return recordedRealSamples(sim, signal, spec)
+	}
+	return bufferedRealSamples(sim, signal.SlaveIndex, signal.ValueReference, spec)
+}
+
+func derivedGetRealSynchronizedSamples(sim *Simulation, status *structs.SimulationStatus, signal1 *structs.TrendSignal, signal2 *structs.TrendSignal, spec structs.TrendSpec) {
+	times1, values1 := trendSignalRealSamples(sim, status, signal1, spec)
+	times2, values2 := trendSignalRealSamples(sim, status, signal2, spec)
+	if len(times1) == 0 || len(times2) == 0 {
+		return
+	}
+	aligned := alignSamples(times1, times2, values2)
+	signal1.TrendXValues = values1
+	signal2.TrendXValues = values1
+	signal2.TrendYValues = aligned
+}
+
+// derivedValue returns the current value of a derived signal, or errNotFinite if it has no finite value.
+func derivedValue(sim *Simulation, derived structs.DerivedSignal) (float64, error) {
+	expr, references, err := compiledDerived(sim, derived)
+	if err != nil {
+		return 0, err
+	}
+	env := make(map[variableReference]float64, len(references))
+	for _, reference := range references {
+		env[reference.reference] = observerGetReal(sim.Observer, reference.slaveIndex, reference.valueReference)
+	}
+	value, finite := expr.eval(env)
+	if !finite {
+		return 0, errNotFinite
+	}
+	return value, nil
+}
+
+func derivedModuleData(sim *Simulation, status *structs.SimulationStatus, view structs.ClientView) (module structs.Module) {
+	for _, subscription := range view.SignalSubscriptions {
+		derived, err := findDerivedSignal(status, view.Module, subscription.Name)
+		if err != nil {
+			continue
+		}
+		value, err := derivedValue(sim, derived)
+		if err == errNotFinite {
+			continue
+		} else if err != nil {
+			log.Println("Could not evaluate derived signal:", err.Error())
+			continue
+		}
+		module.Signals = append(module.Signals, structs.Signal{
+			Name:      derived.Name,
+			Causality: derivedCausality,
+			Type:      "Real",
+			Value:     value,
+		})
+	}
+	module.Name = view.Module
+	return
+}
+
+func derivedTrendSignal(derived structs.DerivedSignal) structs.TrendSignal {
+	return structs.TrendSignal{
+		Module:         derived.Module,
+		SlaveIndex:     -1,
+		Signal:         derived.Name,
+		Causality:      derivedCausality,
+		Type:           "Real",
+		ValueReference: -1,
+	}
+}
+
+func derivedReferences(sim *Simulation, status *structs.SimulationStatus, signal structs.TrendSignal) ([]resolvedReference, error) {
+	derived, err := findDerivedSignal(status, signal.Module, signal.Signal)
+	if err != nil {
+		return nil, err
+	}
+	_, references, err := compiledDerived(sim, derived)
+	return references, err
+}
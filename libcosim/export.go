@@ -0,0 +1,94 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"bytes"
+	"cosim-demo-app/structs"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+// ExportTrend writes the samples currently plotted by a trend as CSV. The first column
+// is the simulation time, followed by one column per Real signal (including derived signals).
+// Signals are resampled onto the time points of the first signal. When there are bookmarks,
+// a last column holds the labels of the bookmarks since the previous time point. The CSV is built
+// under the read lock, and written to the writer after it is released.
+func ExportTrend(sim *Simulation, status *structs.SimulationStatus, trendId string, writer io.Writer) error {
+	var buffer bytes.Buffer
+	sim.lock.RLock()
+	err := exportTrend(sim, status, trendId, &buffer)
+	sim.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = buffer.WriteTo(writer)
+	return err
+}
+
+func exportTrend(sim *Simulation, status *structs.SimulationStatus, trendId string, writer io.Writer) error {
+	if !status.Loaded && status.Run == nil {
+		return errors.New("No simulation is loaded and no run is open")
+	}
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return err
+	}
+	trend := status.Trends[idx]
+
+	header := []string{"Time"}
+	var times []float64
+	var columns [][]float64
+	for i := range trend.TrendSignals {
+		signal := trend.TrendSignals[i]
+		if signal.Type != "Real" {
+			continue
+		}
+		signalTimes, values := trendSignalRealSamples(sim, status, &signal, trend.Spec)
+		if len(signalTimes) == 0 {
+			continue
+		}
+		if times == nil {
+			times = signalTimes
+			columns = append(columns, values)
+		} else {
+			columns = append(columns, alignSamples(times, signalTimes, values))
+		}
+		header = append(header, strCat(signal.Module, ".", signal.Signal))
+	}
+
+	bookmarks := exportedBookmarks(status)
+	withBookmarks := len(bookmarks) > 0
+	if withBookmarks {
+		header = append(header, "Bookmarks")
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+	for i, t := range times {
+		record := make([]string, len(columns)+1)
+		record[0] = strconv.FormatFloat(t, 'g', -1, 64)
+		for j, column := range columns {
+			record[j+1] = strconv.FormatFloat(column[i], 'g', -1, 64)
+		}
+		if withBookmarks {
+			after := math.Inf(-1)
+			if i > 0 {
+				after = times[i-1]
+			}
+			record = append(record, bookmarkLabels(bookmarks, after, t))
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,345 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The expression language used for derived signals supports numbers, the operators
+// + - * / ^, parentheses, a handful of math functions and variable references on the
+// form Module.variable, e.g. "Engine.torque * Engine.omega" or "abs(A.x - B.x)".
+
+type variableReference struct {
+	Module   string
+	Variable string
+}
+
+type expressionNode interface {
+	eval(values map[variableReference]float64) float64
+}
+
+type numberNode float64
+
+type referenceNode variableReference
+
+type unaryNode struct {
+	operand expressionNode
+}
+
+type binaryNode struct {
+	operator    byte
+	left, right expressionNode
+}
+
+type functionNode struct {
+	name      string
+	arguments []expressionNode
+}
+
+type expression struct {
+	root       expressionNode
+	references []variableReference
+}
+
+var expressionFunctions = map[string]int{
+	"abs":   1,
+	"sqrt":  1,
+	"exp":   1,
+	"log":   1,
+	"sin":   1,
+	"cos":   1,
+	"tan":   1,
+	"min":   2,
+	"max":   2,
+	"pow":   2,
+	"atan2": 2,
+}
+
+var expressionConstants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+func (n numberNode) eval(values map[variableReference]float64) float64 {
+	return float64(n)
+}
+
+func (n referenceNode) eval(values map[variableReference]float64) float64 {
+	return values[variableReference(n)]
+}
+
+func (n unaryNode) eval(values map[variableReference]float64) float64 {
+	return -n.operand.eval(values)
+}
+
+func (n binaryNode) eval(values map[variableReference]float64) float64 {
+	left := n.left.eval(values)
+	right := n.right.eval(values)
+	switch n.operator {
+	case '+':
+		return left + right
+	case '-':
+		return left - right
+	case '*':
+		return left * right
+	case '/':
+		return left / right
+	case '^':
+		return math.Pow(left, right)
+	}
+	return math.NaN()
+}
+
+func (n functionNode) eval(values map[variableReference]float64) float64 {
+	args := make([]float64, len(n.arguments))
+	for i, argument := range n.arguments {
+		args[i] = argument.eval(values)
+	}
+	switch n.name {
+	case "abs":
+		return math.Abs(args[0])
+	case "sqrt":
+		return math.Sqrt(args[0])
+	case "exp":
+		return math.Exp(args[0])
+	case "log":
+		return math.Log(args[0])
+	case "sin":
+		return math.Sin(args[0])
+	case "cos":
+		return math.Cos(args[0])
+	case "tan":
+		return math.Tan(args[0])
+	case "min":
+		return math.Min(args[0], args[1])
+	case "max":
+		return math.Max(args[0], args[1])
+	case "pow":
+		return math.Pow(args[0], args[1])
+	case "atan2":
+		return math.Atan2(args[0], args[1])
+	}
+	return math.NaN()
+}
+
+// eval evaluates the expression, and reports whether the result is a finite number. Division by
+// zero, sqrt or log of negative numbers and overflow give results that are not.
+func (e expression) eval(values map[variableReference]float64) (float64, bool) {
+	value := e.root.eval(values)
+	return value, !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+type expressionParser struct {
+	input      string
+	pos        int
+	references []variableReference
+}
+
+func parseExpression(input string) (expression, error) {
+	parser := expressionParser{input: input}
+	root, err := parser.parseSum()
+	if err != nil {
+		return expression{}, err
+	}
+	parser.skipSpace()
+	if parser.pos < len(parser.input) {
+		return expression{}, parser.errorf("Unexpected character ", string(parser.input[parser.pos]))
+	}
+	return expression{root: root, references: parser.references}, nil
+}
+
+func (p *expressionParser) errorf(strs ...string) error {
+	return errors.New(strCat(strCat(strs...), " at position ", strconv.Itoa(p.pos), " in expression: ", p.input))
+}
+
+func (p *expressionParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *expressionParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *expressionParser) parseSum() (expressionNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator := p.peek()
+		if operator != '+' && operator != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) parseProduct() (expressionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator := p.peek()
+		if operator != '*' && operator != '/' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{operand: operand}, nil
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *expressionParser) parsePower() (expressionNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() == '^' {
+		p.pos++
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode{operator: '^', left: base, right: exponent}, nil
+	}
+	return base, nil
+}
+
+func (p *expressionParser) parsePrimary() (expressionNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, p.errorf("Unexpected end")
+	case c == '(':
+		p.pos++
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("Missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return p.parseIdentifier()
+	}
+	return nil, p.errorf("Unexpected character ", string(c))
+}
+
+func (p *expressionParser) parseNumber() (expressionNode, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		isExponent := (c == 'e' || c == 'E') && p.pos+1 < len(p.input)
+		if isExponent && (p.input[p.pos+1] == '+' || p.input[p.pos+1] == '-') {
+			p.pos += 2
+			continue
+		}
+		if c != '.' && !isExponent && (c < '0' || c > '9') {
+			break
+		}
+		p.pos++
+	}
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return nil, p.errorf("Invalid number ", p.input[start:p.pos])
+	}
+	return numberNode(value), nil
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '.' || c == '[' || c == ']' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+func (p *expressionParser) parseIdentifier() (expressionNode, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isIdentifierChar(p.input[p.pos]) {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+
+	if arity, isFunction := expressionFunctions[name]; isFunction && p.peek() == '(' {
+		p.pos++
+		var arguments []expressionNode
+		for p.peek() != ')' {
+			if len(arguments) > 0 {
+				if p.peek() != ',' {
+					return nil, p.errorf("Expected , or ) in call to ", name)
+				}
+				p.pos++
+			}
+			argument, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, argument)
+		}
+		p.pos++
+		if len(arguments) != arity {
+			return nil, p.errorf("Function ", name, " takes ", strconv.Itoa(arity), " argument(s)")
+		}
+		return functionNode{name: name, arguments: arguments}, nil
+	}
+
+	if value, isConstant := expressionConstants[name]; isConstant {
+		return numberNode(value), nil
+	}
+
+	separator := strings.Index(name, ".")
+	if separator <= 0 || separator == len(name)-1 {
+		return nil, p.errorf("Variable references must be on the form Module.variable, got ", name)
+	}
+	reference := variableReference{Module: name[:separator], Variable: name[separator+1:]}
+	p.addReference(reference)
+	return referenceNode(reference), nil
+}
+
+func (p *expressionParser) addReference(reference variableReference) {
+	for _, existing := range p.references {
+		if existing == reference {
+			return
+		}
+	}
+	p.references = append(p.references, reference)
+}
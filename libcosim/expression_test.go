@@ -0,0 +1,108 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	values := map[variableReference]float64{
+		{Module: "Engine", Variable: "torque"}: 200,
+		{Module: "Engine", Variable: "omega"}:  3,
+		{Module: "A", Variable: "x"}:           1.5,
+		{Module: "B", Variable: "x"}:           4,
+		{Module: "Ship", Variable: "pos[1]"}:   -2,
+	}
+	tests := []struct {
+		input string
+		value float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"12 / 3 / 2", 2},
+		{"-2 ^ 2", -4},
+		{"2 ^ 3 ^ 2", 512},
+		{"2 ^ -1", 0.5},
+		{"+3 - -3", 6},
+		{"1.5e2 + 2E-1", 150.2},
+		{".5", 0.5},
+		{"Engine.torque * Engine.omega", 600},
+		{"abs(A.x - B.x)", 2.5},
+		{"max(A.x, min(B.x, 2))", 2},
+		{"pow(2, 10) + atan2(0, 1)", 1024},
+		{"sqrt(16) + exp(0) + log(e)", 6},
+		{"sin(0) + cos(pi) + tan(0)", -1},
+		{"Ship.pos[1] * 2", -4},
+	}
+	for _, test := range tests {
+		expr, err := parseExpression(test.input)
+		if err != nil {
+			t.Errorf("parseExpression(%q) failed: %v", test.input, err)
+			continue
+		}
+		value, finite := expr.eval(values)
+		if !finite || math.Abs(value-test.value) > 1e-9 {
+			t.Errorf("%q evaluated to %v, finite %v, want %v", test.input, value, finite, test.value)
+		}
+	}
+}
+
+func TestParseExpressionReferences(t *testing.T) {
+	expr, err := parseExpression("A.x * B.y + A.x - pi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []variableReference{{Module: "A", Variable: "x"}, {Module: "B", Variable: "y"}}
+	if !reflect.DeepEqual(expr.references, want) {
+		t.Errorf("got references %v, want %v", expr.references, want)
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 + 2)",
+		"2 * * 3",
+		"torque",
+		"Engine.",
+		"sqrt(1, 2)",
+		"max(1)",
+		"max(1 2)",
+		"1.2.3",
+		"1 # 2",
+	} {
+		if _, err := parseExpression(input); err == nil {
+			t.Errorf("parseExpression(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestEvalNotFinite(t *testing.T) {
+	values := map[variableReference]float64{{Module: "A", Variable: "x"}: 0}
+	for _, input := range []string{"1 / A.x", "0 / A.x", "sqrt(A.x - 1)", "log(A.x)", "log(-1)", "exp(1000)", "10 ^ 400"} {
+		expr, err := parseExpression(input)
+		if err != nil {
+			t.Errorf("parseExpression(%q) failed: %v", input, err)
+			continue
+		}
+		if value, finite := expr.eval(values); finite {
+			t.Errorf("%q evaluated to the finite %v", input, value)
+		}
+	}
+}
+
+func TestAlignSamples(t *testing.T) {
+	aligned := alignSamples([]float64{0, 1, 2, 3}, []float64{0, 1.5, 2.5}, []float64{10, 20, 30})
+	want := []float64{10, 10, 20, 30}
+	if !reflect.DeepEqual(aligned, want) {
+		t.Errorf("got %v, want %v", aligned, want)
+	}
+}
@@ -0,0 +1,231 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"encoding/binary"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The trend observer only buffers the last samples of each variable. To be able to zoom
+// back on long runs, observed Real trend variables are regularly drained to one file per
+// variable in a history folder. Each file is a sequence of fixed size records holding the
+// simulation time and the value as little endian float64, sorted by time.
+
+const historyFolder = "trend-history"
+const historyRecordSize = 16
+const historyDrainInterval = 2 * time.Second
+const historyMaxPoints = 5000
+
+type historySeries struct {
+	file     *os.File
+	count    int64
+	lastTime float64
+}
+
+type trendHistory struct {
+	mutex     sync.Mutex
+	dir       string
+	temporary bool
+	series    map[observedVariable]*historySeries
+}
+
+// newTrendHistory creates the history store in the log folder, or in a temporary
+// folder which is removed on teardown when the simulation has no log folder.
+func newTrendHistory(logDir string) *trendHistory {
+	history := &trendHistory{series: map[observedVariable]*historySeries{}}
+	var err error
+	if len(logDir) > 0 {
+		history.dir = filepath.Join(logDir, historyFolder, time.Now().Format("20060102_150405"))
+		err = os.MkdirAll(history.dir, 0755)
+	} else {
+		history.dir, err = ioutil.TempDir("", historyFolder)
+		history.temporary = true
+	}
+	if err != nil {
+		log.Println("Could not create trend history folder, trend history is disabled:", err)
+		return nil
+	}
+	return history
+}
+
+func (history *trendHistory) close() {
+	if history == nil {
+		return
+	}
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	for _, series := range history.series {
+		series.file.Close()
+	}
+	history.series = map[observedVariable]*historySeries{}
+	if history.temporary {
+		os.RemoveAll(history.dir)
+	}
+}
+
+func (history *trendHistory) seriesFor(variable observedVariable) (*historySeries, error) {
+	if series, exists := history.series[variable]; exists {
+		return series, nil
+	}
+	name := strCat(strconv.Itoa(variable.slaveIndex), "_", strconv.Itoa(variable.valueReference), ".bin")
+	file, err := os.OpenFile(filepath.Join(history.dir, name), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	series := &historySeries{file: file, lastTime: math.Inf(-1)}
+	history.series[variable] = series
+	return series, nil
+}
+
+func (history *trendHistory) append(variable observedVariable, times []float64, values []float64) error {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	series, err := history.seriesFor(variable)
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, 0, historyRecordSize*len(times))
+	record := make([]byte, historyRecordSize)
+	var appended int64
+	lastTime := series.lastTime
+	for i, t := range times {
+		if t <= lastTime {
+			continue
+		}
+		binary.LittleEndian.PutUint64(record[0:8], math.Float64bits(t))
+		binary.LittleEndian.PutUint64(record[8:16], math.Float64bits(values[i]))
+		buffer = append(buffer, record...)
+		lastTime = t
+		appended++
+	}
+	if appended == 0 {
+		return nil
+	}
+	_, err = series.file.Write(buffer)
+	if err != nil {
+		return err
+	}
+	series.count += appended
+	series.lastTime = lastTime
+	return nil
+}
+
+func (series *historySeries) record(n int64) (t float64, value float64, err error) {
+	record := make([]byte, historyRecordSize)
+	_, err = series.file.ReadAt(record, n*historyRecordSize)
+	if err != nil {
+		return
+	}
+	t = math.Float64frombits(binary.LittleEndian.Uint64(record[0:8]))
+	value = math.Float64frombits(binary.LittleEndian.Uint64(record[8:16]))
+	return
+}
+
+// read returns at most historyMaxPoints samples with begin <= time < end, evenly decimated.
+func (history *trendHistory) read(variable observedVariable, begin float64, end float64) (times []float64, values []float64) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	series, exists := history.series[variable]
+	if !exists || series.count == 0 {
+		return
+	}
+
+	var readErr error
+	firstAtOrAfter := func(t float64) int64 {
+		return int64(sort.Search(int(series.count), func(n int) bool {
+			recordTime, _, err := series.record(int64(n))
+			if err != nil {
+				readErr = err
+				return true
+			}
+			return recordTime >= t
+		}))
+	}
+	first := firstAtOrAfter(begin)
+	last := firstAtOrAfter(end)
+	if readErr != nil {
+		log.Println("Could not read trend history:", readErr)
+		return nil, nil
+	}
+
+	stride := (last - first + historyMaxPoints - 1) / historyMaxPoints
+	if stride < 1 {
+		stride = 1
+	}
+	for n := first; n < last; n += stride {
+		t, value, err := series.record(n)
+		if err != nil {
+			log.Println("Could not read trend history:", err)
+			break
+		}
+		times = append(times, t)
+		values = append(values, value)
+	}
+	return times, values
+}
+
+// drainTrendHistory moves the samples of all observed Real trend variables
+// that were added since the last drain from the trend observer to the history.
+func drainTrendHistory(sim *Simulation, status *structs.SimulationStatus) {
+	if sim.history == nil || !status.Loaded {
+		return
+	}
+	now := getExecutionStatus(sim.Execution).time
+	for variable := range sim.trendObservations {
+		if variable.valueType != "Real" {
+			continue
+		}
+		sim.history.mutex.Lock()
+		since := math.Inf(-1)
+		if series, exists := sim.history.series[variable]; exists {
+			since = series.lastTime
+		}
+		sim.history.mutex.Unlock()
+
+		spec := structs.TrendSpec{Auto: false, Begin: math.Max(since, 0), End: now}
+		times, values := observerRealSamples(sim.TrendObserver, variable.slaveIndex, variable.valueReference, spec)
+		if len(times) == 0 {
+			continue
+		}
+		err := sim.history.append(variable, times, values)
+		if err != nil {
+			log.Println("Could not write trend history:", err)
+		}
+	}
+}
+
+// bufferedRealSamples returns the samples of a Real variable for the trend spec, completing
+// the samples from the trend observer with samples from the history where the buffer falls short.
+func bufferedRealSamples(sim *Simulation, slaveIndex int, valueReference int, spec structs.TrendSpec) ([]float64, []float64) {
+	times, values := observerRealSamples(sim.TrendObserver, slaveIndex, valueReference, spec)
+	if sim.history == nil || spec.Auto {
+		return times, values
+	}
+	if len(times) > 0 && times[0] <= spec.Begin {
+		return times, values
+	}
+
+	end := spec.End
+	if len(times) > 0 {
+		end = times[0]
+	}
+	variable := observedVariable{slaveIndex, "Real", valueReference}
+	historyTimes, historyValues := sim.history.read(variable, spec.Begin, end)
+	if len(historyTimes) == 0 {
+		return times, values
+	}
+	return append(historyTimes, times...), append(historyValues, values...)
+}
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -483,6 +484,7 @@ func fetchManipulatedVariables(execution *C.cosim_execution) []structs.Manipulat
 }
 
 func simulationTeardown(sim *Simulation) (bool, string) {
+	closeStepStreams(sim)
 	executionDestroy(sim.Execution)
 	observerDestroy(sim.Observer)
 	observerDestroy(sim.TrendObserver)
@@ -499,6 +501,14 @@ func simulationTeardown(sim *Simulation) (bool, string) {
 	sim.LocalSlaves = []*C.cosim_slave{}
 	sim.Observer = nil
 	sim.TrendObserver = nil
+	sim.trendObservations = nil
+	sim.triggerScans = nil
+	sim.derivedSignals = nil
+	sim.scenarioEvents = nil
+	sim.executionFailed = false
+	sim.bookmarksPath = ""
+	sim.history.close()
+	sim.history = nil
 	sim.FileObserver = nil
 	sim.OverrideManipulator = nil
 	sim.ScenarioManager = nil
@@ -653,15 +663,24 @@ func initializeSimulation(sim *Simulation, status *structs.SimulationStatus, con
 	sim.Observer = observer
 	sim.TrendObserver = trendObserver
 	sim.FileObserver = fileObserver
+	sim.history = newTrendHistory(logDir)
 	sim.OverrideManipulator = manipulator
 	sim.ScenarioManager = scenarioManager
 	sim.MetaData = &metaData
 
-	setupPlotsFromConfig(sim, status, config.configDir)
-
 	return true, "Simulation loaded successfully", config.configDir
 }
 
+func setupPlotsAfterLoad(sim *Simulation, status *structs.SimulationStatus, configDir string, shorty *structs.ShortLivedData, message string) string {
+	shorty.PlotConfigReport = setupPlotsFromConfig(sim, status, configDir)
+	if shorty.PlotConfigReport != nil {
+		if summary := reportSummary(shorty.PlotConfigReport); len(summary) > 0 {
+			return strCat(message, ". ", summary)
+		}
+	}
+	return message
+}
+
 func resetSimulation(sim *Simulation, status *structs.SimulationStatus, configPath string, logDir string) (bool, string, string) {
 	var success = false
 	var message = ""
@@ -675,7 +694,11 @@ func resetSimulation(sim *Simulation, status *structs.SimulationStatus, configPa
 		status.Status = "stopped"
 		status.ConfigDir = ""
 		status.Trends = []structs.Trend{}
-		status.Module = ""
+		status.DerivedSignals = nil
+		status.Captures = nil
+		status.Bookmarks = nil
+		status.LogDir = ""
+		status.View = NewClientView()
 		success, message = simulationTeardown(sim)
 		log.Println(message)
 	}
@@ -686,9 +709,81 @@ func resetSimulation(sim *Simulation, status *structs.SimulationStatus, configPa
 	return success, message, configDir
 }
 
-func executeCommand(cmd []string, sim *Simulation, status *structs.SimulationStatus) (shorty structs.ShortLivedData, feedback structs.CommandFeedback) {
+// trendIdArgument gives the position of the trend id argument of each command operating on an existing trend.
+var trendIdArgument = map[string]int{
+	"addtotrend":            3,
+	"set-axis":              1,
+	"set-signal-settings":   1,
+	"untrend":               1,
+	"removefromtrend":       1,
+	"reorder-trend-signal":  1,
+	"move-trend-signal":     1,
+	"removetrend":           1,
+	"setlabel":              1,
+	"trend-zoom":            1,
+	"trend-zoom-reset":      1,
+	"set-trigger":           1,
+	"arm-trigger":           1,
+	"remove-trigger":        1,
+	"set-reference-overlay": 1,
+}
+
+// commandArguments gives the number of arguments each command needs at least. Commands come from
+// all the servers, so a command missing some is refused rather than indexed past its end.
+var commandArguments = map[string]int{
+	"load":                       2,
+	"reset":                      2,
+	"set-custom-realtime-factor": 1,
+	"set-steps-to-monitor":       1,
+	"newtrend":                   2,
+	"addtotrend":                 3,
+	"set-axis":                   4,
+	"set-signal-settings":        3,
+	"untrend":                    1,
+	"removefromtrend":            3,
+	"removetrend":                1,
+	"reorder-trend-signal":       3,
+	"move-trend-signal":          4,
+	"active-trend":               1,
+	"setlabel":                   2,
+	"trend-zoom":                 3,
+	"trend-zoom-reset":           2,
+	"set-trigger":                7,
+	"arm-trigger":                1,
+	"remove-trigger":             1,
+	"view-capture":               1,
+	"remove-capture":             1,
+	"load-reference":             1,
+	"set-reference-overlay":      4,
+	"open-run":                   1,
+	"add-derived-signal":         2,
+	"remove-derived-signal":      1,
+	"set-value":                  4,
+	"reset-value":                3,
+	"add-bookmark":               1,
+	"set-bookmark-label":         2,
+	"remove-bookmark":            1,
+	"set-update-interval":        1,
+	"unwatch":                    2,
+	"load-scenario":              1,
+	"parse-scenario":             1,
+}
+
+// executeCommand also tells whether the command is known, so unknown commands can be counted together.
+func executeCommand(cmd []string, sim *Simulation, status *structs.SimulationStatus, view *structs.ClientView) (shorty structs.ShortLivedData, feedback structs.CommandFeedback, known bool) {
+	known = true
 	var success = false
 	var message = "No feedback implemented for this command"
+	var trendId int
+	if count := commandArguments[cmd[0]]; len(cmd)-1 < count {
+		message = strCat("Command ", cmd[0], " needs ", strconv.Itoa(count), " arguments, got ", strconv.Itoa(len(cmd)-1))
+		return shorty, structs.CommandFeedback{Success: false, Message: message, Command: cmd[0], Code: 400}, known
+	}
+	if position, isTrendCommand := trendIdArgument[cmd[0]]; isTrendCommand && len(cmd) > position {
+		if _, err := findTrend(status, cmd[position]); err != nil {
+			return shorty, structs.CommandFeedback{Success: false, Message: err.Error(), Command: cmd[0], Code: 404}, known
+		}
+	}
 	switch cmd[0] {
 	case "load":
 		status.Loading = true
@@ -697,10 +792,15 @@ func executeCommand(cmd []string, sim *Simulation, status *structs.SimulationSta
 		if success {
 			status.Loaded = true
 			status.ConfigDir = configDir
+			status.LogDir = cmd[2]
+			status.Bookmarks = nil
+			sim.bookmarksPath = runBookmarksPath(status.LogDir, time.Now())
+			sim.loadGeneration++
 			status.Status = "pause"
 			shorty.ModuleData = sim.MetaData
 			scenarios := findScenarios(status)
 			shorty.Scenarios = &scenarios
+			message = setupPlotsAfterLoad(sim, status, configDir, &shorty, message)
 		}
 		status.Loading = false
 	case "teardown":
@@ -708,7 +808,10 @@ func executeCommand(cmd []string, sim *Simulation, status *structs.SimulationSta
 		status.Status = "stopped"
 		status.ConfigDir = ""
 		status.Trends = []structs.Trend{}
-		status.Module = ""
+		status.DerivedSignals = nil
+		status.Captures = nil
+		status.Bookmarks = nil
+		status.View = NewClientView()
 		success, message = simulationTeardown(sim)
 		shorty.ModuleData = sim.MetaData
 	case "reset":
@@ -718,10 +821,15 @@ func executeCommand(cmd []string, sim *Simulation, status *structs.SimulationSta
 		if success {
 			status.Loaded = true
 			status.ConfigDir = configDir
+			status.LogDir = cmd[2]
+			status.Bookmarks = nil
+			sim.bookmarksPath = runBookmarksPath(status.LogDir, time.Now())
+			sim.loadGeneration++
 			status.Status = "pause"
 			shorty.ModuleData = sim.MetaData
 			scenarios := findScenarios(status)
 			shorty.Scenarios = &scenarios
+			message = setupPlotsAfterLoad(sim, status, configDir, &shorty, message)
 		}
 		status.Loading = false
 	case "pause":
@@ -739,31 +847,95 @@ func executeCommand(cmd []string, sim *Simulation, status *structs.SimulationSta
 	case "set-steps-to-monitor":
 		success, message = executionSetStepsToMonitor(sim.Execution, cmd[1])
 	case "newtrend":
-		success, message = addNewTrend(status, cmd[1], cmd[2])
+		success, message, trendId = addNewTrend(status, cmd[1], cmd[2])
 	case "addtotrend":
-		success, message = addToTrend(sim, status, cmd[1], cmd[2], cmd[3])
+		var axis string
+		if len(cmd) > 4 {
+			axis = cmd[4]
+		}
+		success, message = addToTrend(sim, status, cmd[1], cmd[2], cmd[3], axis)
+	case "set-axis":
+		success, message = setTrendSignalAxis(status, cmd[1], cmd[2], cmd[3], cmd[4])
+	case "set-signal-settings":
+		success, message = setTrendSignalSettings(status, cmd[1], cmd[2], cmd[3], cmd[4:])
 	case "untrend":
 		success, message = removeAllFromTrend(sim, status, cmd[1])
+	case "removefromtrend":
+		success, message = removeFromTrend(sim, status, cmd[1], cmd[2], cmd[3])
 	case "removetrend":
-		success, message = removeTrend(status, cmd[1])
+		success, message = removeTrend(sim, status, cmd[1])
+	case "reorder-trend-signal":
+		success, message = reorderTrendSignal(status, cmd[1], cmd[2], cmd[3])
+	case "move-trend-signal":
+		success, message = moveTrendSignal(sim, status, cmd[1], cmd[2], cmd[3], cmd[4])
 	case "active-trend":
-		success, message = activeTrend(status, cmd[1])
+		success, message = activeTrend(status, view, cmd[1])
 	case "setlabel":
 		success, message = setTrendLabel(status, cmd[1], cmd[2])
 	case "trend-zoom":
-		idx, _ := strconv.Atoi(cmd[1])
-		status.Trends[idx].Spec = structs.TrendSpec{Auto: false, Begin: parseFloat(cmd[2]), End: parseFloat(cmd[3])}
-		success = true
-		message = strCat("Plotting values from ", cmd[2], " to ", cmd[3])
+		success, message = setTrendSpec(status, cmd[1], structs.TrendSpec{Auto: false, Begin: parseFloat(cmd[2]), End: parseFloat(cmd[3])})
+		if success {
+			message = strCat("Plotting values from ", cmd[2], " to ", cmd[3])
+		}
 	case "trend-zoom-reset":
-		idx, _ := strconv.Atoi(cmd[1])
-		status.Trends[idx].Spec = structs.TrendSpec{Auto: true, Range: parseFloat(cmd[2])}
-		success = true
-		message = strCat("Plotting last ", cmd[2], " seconds")
+		success, message = setTrendSpec(status, cmd[1], structs.TrendSpec{Auto: true, Range: parseFloat(cmd[2])})
+		if success {
+			message = strCat("Plotting last ", cmd[2], " seconds")
+		}
+	case "load-plot-config":
+		var name string
+		if len(cmd) > 1 {
+			name = cmd[1]
+		}
+		success, message, shorty.PlotConfigReport = loadPlotConfig(sim, status, name)
+	case "set-trigger":
+		success, message = setTrigger(sim, status, cmd[1], cmd[2], cmd[3], cmd[4], cmd[5], cmd[6], cmd[7])
+	case "arm-trigger":
+		success, message = armTrigger(sim, status, cmd[1])
+	case "remove-trigger":
+		success, message = removeTrigger(sim, status, cmd[1])
+	case "view-capture":
+		success, message, shorty.Capture = viewCapture(status, cmd[1])
+	case "remove-capture":
+		success, message = removeCapture(status, cmd[1])
+	case "load-reference":
+		var module string
+		if len(cmd) > 2 {
+			module = cmd[2]
+		}
+		success, message = loadReference(sim, status, cmd[1], module)
+	case "clear-reference":
+		success, message = clearReference(sim, status)
+	case "set-reference-overlay":
+		success, message = setReferenceOverlay(status, cmd[1], cmd[2], cmd[3], cmd[4])
+	case "open-run":
+		var id string
+		if len(cmd) > 2 {
+			id = cmd[2]
+		}
+		success, message = openRun(sim, status, cmd[1], id)
+	case "close-run":
+		success, message = closeRun(sim, status)
+	case "add-derived-signal":
+		success, message = addDerivedSignal(sim, status, cmd[1], cmd[2])
+	case "remove-derived-signal":
+		success, message = removeDerivedSignal(sim, status, cmd[1])
 	case "set-value":
 		success, message = setVariableValue(sim, cmd[1], cmd[2], cmd[3], cmd[4])
+		if success {
+			autoBookmark(sim, status, strCat("Set ", describeVariable(sim, cmd[1], cmd[2], cmd[3]), " = ", cmd[4]), bookmarkOverride)
+		}
 	case "reset-value":
 		success, message = resetVariableValue(sim, cmd[1], cmd[2], cmd[3])
+		if success {
+			autoBookmark(sim, status, strCat("Reset ", describeVariable(sim, cmd[1], cmd[2], cmd[3])), bookmarkOverride)
+		}
+	case "add-bookmark":
+		success, message = addBookmark(sim, status, cmd[1], cmd[2:])
+	case "set-bookmark-label":
+		success, message = setBookmarkLabel(sim, status, cmd[1], cmd[2])
+	case "remove-bookmark":
+		success, message = removeBookmark(sim, status, cmd[1])
 	case "get-module-data":
 		shorty.ModuleData = sim.MetaData
 		scenarios := findScenarios(status)
@@ -771,11 +943,28 @@ func executeCommand(cmd []string, sim *Simulation, status *structs.SimulationSta
 		success = true
 		message = "Fetched metadata"
 	case "signals":
-		success, message = setSignalSubscriptions(status, cmd)
+		success, message = setSignalSubscriptions(view, cmd)
+	case "set-update-interval":
+		success, message = setUpdateInterval(view, cmd[1])
+	case "watch":
+		success, message = watchVariables(sim, status, view, cmd[1:])
+	case "unwatch":
+		success, message = unwatchVariable(view, cmd[1], cmd[2])
+	case "clear-watch":
+		success, message = clearWatchList(view)
 	case "load-scenario":
 		success, message = loadScenario(sim, status, cmd[1])
+		if success {
+			autoBookmark(sim, status, strCat("Loaded scenario ", cmd[1]), bookmarkScenario)
+			scheduleScenarioEvents(sim, status, cmd[1])
+		}
 	case "abort-scenario":
 		success, message = abortScenario(sim.ScenarioManager)
+		if success {
+			checkScenarioEvents(sim, status)
+			sim.scenarioEvents = nil
+			autoBookmark(sim, status, strCat(abortedScenarioLabel, status.CurrentScenario), bookmarkScenario)
+		}
 	case "parse-scenario":
 		scenario, err := parseScenario(status, cmd[1])
 		if err != nil {
@@ -787,28 +976,87 @@ func executeCommand(cmd []string, sim *Simulation, status *structs.SimulationSta
 			shorty.Scenario = &scenario
 		}
 	default:
+		known = false
 		message = "Unknown command, this is not good"
 		fmt.Println(message, cmd)
 	}
-	return shorty, structs.CommandFeedback{Success: success, Message: message, Command: cmd[0]}
+	return shorty, structs.CommandFeedback{Success: success, Message: message, Command: cmd[0], TrendId: trendId}, known
+}
+
+// monitorInterval is how often CommandLoop checks plot triggers and the execution state, and caches the execution metrics.
+const monitorInterval = 250 * time.Millisecond
+
+// simulationCommands replace the loaded simulation, which invalidates the views of all clients.
+var simulationCommands = map[string]bool{
+	"load":     true,
+	"teardown": true,
+	"reset":    true,
 }
 
-func CommandLoop(state chan structs.JsonResponse, sim *Simulation, command chan []string, status *structs.SimulationStatus) {
+// CommandLoop executes the commands of all clients, and sends the response to the client that sent the command.
+func CommandLoop(clients *Clients, sim *Simulation, command chan structs.ClientCommand, status *structs.SimulationStatus, metrics *Metrics) {
+	historyTicker := time.NewTicker(historyDrainInterval)
+	defer historyTicker.Stop()
+	monitorTicker := time.NewTicker(monitorInterval)
+	defer monitorTicker.Stop()
+	stepStreamTicker := time.NewTicker(stepStreamInterval)
+	defer stepStreamTicker.Stop()
 	for {
 		select {
-		case cmd := <-command:
-			shorty, feedback := executeCommand(cmd, sim, status)
-			state <- GenerateJsonResponse(status, sim, feedback, shorty)
+		case clientCommand := <-command:
+			sim.lock.Lock()
+			started := time.Now()
+			cmd := clientCommand.Command
+			view, isClient := clients.view(clientCommand.ClientId)
+			if !isClient {
+				view = status.View
+			}
+			shorty, feedback, known := executeCommand(cmd, sim, status, &view)
+			if simulationCommands[cmd[0]] {
+				clients.resetViews()
+				view = resetView(view)
+			}
+			if isClient {
+				clients.setView(clientCommand.ClientId, view)
+			} else {
+				status.View = view
+			}
+			// The command may have changed what the other clients see as well.
+			clients.markDue(clientCommand.ClientId, feedback, shorty)
+			// Unknown commands are counted together, so clients can't add names without bounds.
+			if known {
+				metrics.observeCommand(cmd[0], time.Since(started))
+			} else {
+				metrics.observeCommand("unknown", time.Since(started))
+			}
+			metrics.setExecution(executionMetrics(sim, status))
+			sim.lock.Unlock()
+		case <-historyTicker.C:
+			sim.lock.Lock()
+			drainTrendHistory(sim, status)
+			sim.lock.Unlock()
+		case <-stepStreamTicker.C:
+			sim.lock.Lock()
+			sendStepSamples(sim, status)
+			sim.lock.Unlock()
+		case <-monitorTicker.C:
+			sim.lock.Lock()
+			checkTriggers(sim, status)
+			checkScenarioEvents(sim, status)
+			checkExecutionError(sim, status)
+			metrics.setExecution(executionMetrics(sim, status))
+			sim.lock.Unlock()
 		}
+		metrics.markCommandLoop()
 	}
 }
 
-func setSignalSubscriptions(status *structs.SimulationStatus, cmd []string) (bool, string) {
+func setSignalSubscriptions(view *structs.ClientView, cmd []string) (bool, string) {
 	var variables []structs.Variable
 	var message = "Successfully set signal subscriptions"
 	var success = true
 	if len(cmd) > 1 {
-		status.Module = cmd[1]
+		view.Module = cmd[1]
 		for j := 2; j < (len(cmd) - 3); j += 4 {
 			name := cmd[j]
 			caus := cmd[j+1]
@@ -832,7 +1080,7 @@ func setSignalSubscriptions(status *structs.SimulationStatus, cmd []string) (boo
 	} else {
 		message = "Successfully reset signal subscriptions"
 	}
-	status.SignalSubscriptions = variables
+	view.SignalSubscriptions = variables
 	return success, message
 }
 
@@ -856,19 +1104,24 @@ func findVariable(fmu structs.FMU, variableName string) (foundVariable structs.V
 	return foundVariable, errors.New("Variable with name " + variableName + " does not exist for simulator " + fmu.Name)
 }
 
-func findModuleData(status *structs.SimulationStatus, metaData *structs.MetaData, observer *C.cosim_observer) (module structs.Module) {
-	if len(status.SignalSubscriptions) > 0 {
+func findModuleData(status *structs.SimulationStatus, view structs.ClientView, sim *Simulation) (module structs.Module) {
+	if len(view.SignalSubscriptions) > 0 && isDerivedModule(status, view.Module) {
+		return derivedModuleData(sim, status, view)
+	}
+	metaData := sim.MetaData
+	observer := sim.Observer
+	if len(view.SignalSubscriptions) > 0 {
 
-		slave, err := findFmu(metaData, status.Module)
+		slave, err := findFmu(metaData, view.Module)
 		if err != nil {
 			log.Println(err.Error())
 			return
 		}
 		slaveIndex := slave.ExecutionIndex
-		realSignals := observerGetReals(observer, status.SignalSubscriptions, slaveIndex)
-		intSignals := observerGetIntegers(observer, status.SignalSubscriptions, slaveIndex)
-		boolSignals := observerGetBooleans(observer, status.SignalSubscriptions, slaveIndex)
-		stringSignals := observerGetStrings(observer, status.SignalSubscriptions, slaveIndex)
+		realSignals := observerGetReals(observer, view.SignalSubscriptions, slaveIndex)
+		intSignals := observerGetIntegers(observer, view.SignalSubscriptions, slaveIndex)
+		boolSignals := observerGetBooleans(observer, view.SignalSubscriptions, slaveIndex)
+		stringSignals := observerGetStrings(observer, view.SignalSubscriptions, slaveIndex)
 		var signals []structs.Signal
 		signals = append(signals, realSignals...)
 		signals = append(signals, intSignals...)
@@ -876,7 +1129,7 @@ func findModuleData(status *structs.SimulationStatus, metaData *structs.MetaData
 		signals = append(signals, stringSignals...)
 
 		module.Signals = signals
-		module.Name = status.Module
+		module.Name = view.Module
 	}
 	return
 }
@@ -885,7 +1138,14 @@ func GetSignalValue(module string, cardinality string, signal string) int {
 	return 1
 }
 
-func GenerateJsonResponse(status *structs.SimulationStatus, sim *Simulation, feedback structs.CommandFeedback, shorty structs.ShortLivedData) structs.JsonResponse {
+// GenerateJsonResponse returns the state as seen from a view, outside the command loop.
+func GenerateJsonResponse(status *structs.SimulationStatus, sim *Simulation, view structs.ClientView, feedback structs.CommandFeedback, shorty structs.ShortLivedData) structs.JsonResponse {
+	sim.lock.RLock()
+	defer sim.lock.RUnlock()
+	return generateJsonResponse(status, sim, view, feedback, shorty)
+}
+
+func generateJsonResponse(status *structs.SimulationStatus, sim *Simulation, view structs.ClientView, feedback structs.CommandFeedback, shorty structs.ShortLivedData) structs.JsonResponse {
 	var response = structs.JsonResponse{
 		Loading:    status.Loading,
 		Loaded:     status.Loaded,
@@ -904,16 +1164,22 @@ func GenerateJsonResponse(status *structs.SimulationStatus, sim *Simulation, fee
 		response.RealTimeFactorTarget = execStatus.realTimeFactorTarget
 		response.IsRealTimeSimulation = execStatus.isRealTimeSimulation
 		response.StepsToMonitor = execStatus.stepsToMonitor
-		response.Module = findModuleData(status, sim.MetaData, sim.Observer)
+		response.Module = findModuleData(status, view, sim)
+		response.WatchList = watchListData(sim, status, view)
 		response.ConfigDir = status.ConfigDir
-		generatePlotData(sim, status)
-		response.Trends = status.Trends
+		response.Trends = generatePlotData(sim, status, view.ActiveTrend)
 		response.ManipulatedVariables = fetchManipulatedVariables(sim.Execution)
-		if sim.ScenarioManager != nil && isScenarioRunning(sim.ScenarioManager) {
-			response.RunningScenario = status.CurrentScenario
-		}
+		response.DerivedSignals = status.DerivedSignals
+		response.Captures = captureSummaries(status)
+		response.Bookmarks = status.Bookmarks
+		response.Reference = status.Reference
+		response.RunningScenario = RunningScenario(sim, status)
 
 	}
+	if !status.Loaded && status.Run != nil {
+		response.Trends = generatePlotData(sim, status, view.ActiveTrend)
+	}
+	response.Run = status.Run
 	if (structs.CommandFeedback{}) != feedback {
 		response.Feedback = &feedback
 	}
@@ -927,14 +1193,35 @@ func GenerateJsonResponse(status *structs.SimulationStatus, sim *Simulation, fee
 		if shorty.ModuleData != nil {
 			response.ModuleData = shorty.ModuleData
 		}
+		if shorty.PlotConfigReport != nil {
+			response.PlotConfigReport = shorty.PlotConfigReport
+		}
+		if shorty.Capture != nil {
+			response.Capture = shorty.Capture
+		}
 	}
 	return response
 }
 
-func StateUpdateLoop(state chan structs.JsonResponse, simulationStatus *structs.SimulationStatus, sim *Simulation) {
+// stateUpdateResolution is how often StateUpdateLoop looks for clients due for an update.
+const stateUpdateResolution = 10 * time.Millisecond
+
+// StateUpdateLoop sends every client the state as seen from its view, at the update interval of the client
+// and after commands.
+func StateUpdateLoop(clients *Clients, simulationStatus *structs.SimulationStatus, sim *Simulation, metrics *Metrics) {
 	for {
-		state <- GenerateJsonResponse(simulationStatus, sim, structs.CommandFeedback{}, structs.ShortLivedData{})
-		time.Sleep(1000 * time.Millisecond)
+		sim.lock.RLock()
+		running := simulationStatus.Loaded && simulationStatus.Status == "play"
+		started := time.Now()
+		due := clients.due(started, running)
+		for id, update := range due {
+			clients.send(id, generateJsonResponse(simulationStatus, sim, update.view, update.feedback, update.shorty))
+		}
+		sim.lock.RUnlock()
+		if len(due) > 0 {
+			metrics.observeBroadcast(time.Since(started))
+		}
+		time.Sleep(stateUpdateResolution)
 	}
 }
 
@@ -1027,6 +1314,44 @@ type Simulation struct {
 	ScenarioManager     *C.cosim_manipulator
 	MetaData            *structs.MetaData
 	LocalSlaves         []*C.cosim_slave
+	trendObservations   map[observedVariable]int
+	history             *trendHistory
+	triggerScans        map[int]triggerScan
+	derivedSignals      map[structs.DerivedSignal]compiledDerivedSignal
+	scenarioEvents      []scenarioEvent
+	executionFailed     bool
+	bookmarksPath       string
+	lastBookmarkId      int
+	loadGeneration      int
+	reference           map[string]referenceSeries
+	run                 map[string]referenceSeries
+	stepStreams         []*StepStream
+	// lock is held by the command loop while it uses the simulation, and by the readers outside it.
+	lock sync.RWMutex
+}
+
+// ErrNotLoaded is returned by the readers outside the command loop when no simulation is loaded.
+var ErrNotLoaded = errors.New("No simulation is loaded")
+
+// LoadedMetaData returns the metadata of the loaded simulation.
+func LoadedMetaData(sim *Simulation, status *structs.SimulationStatus) (*structs.MetaData, error) {
+	sim.lock.RLock()
+	defer sim.lock.RUnlock()
+	if !status.Loaded {
+		return nil, ErrNotLoaded
+	}
+	return sim.MetaData, nil
+}
+
+// LoadedConfigDir returns the configuration folder of the loaded simulation, or "" if none is loaded,
+// and a generation counting every load and reset.
+func LoadedConfigDir(sim *Simulation, status *structs.SimulationStatus) (string, int) {
+	sim.lock.RLock()
+	defer sim.lock.RUnlock()
+	if !status.Loaded {
+		return "", sim.loadGeneration
+	}
+	return status.ConfigDir, sim.loadGeneration
 }
 
 func CreateEmptySimulation() Simulation {
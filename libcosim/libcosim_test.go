@@ -0,0 +1,25 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"testing"
+)
+
+func TestExecuteCommandMissingArguments(t *testing.T) {
+	for name, count := range commandArguments {
+		cmd := []string{name}
+		for i := 1; i < count; i++ {
+			cmd = append(cmd, "1")
+		}
+		view := NewClientView()
+		status := &structs.SimulationStatus{Trends: []structs.Trend{{Id: 1}}}
+		_, feedback, known := executeCommand(cmd, &Simulation{MetaData: &structs.MetaData{}}, status, &view)
+		if !known || feedback.Success || feedback.Code != 400 {
+			t.Errorf("%v got feedback %+v, want code 400", cmd, feedback)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"sync"
+	"time"
+)
+
+// Metrics times the commands, per command name, and the state broadcasts, for the /metrics endpoint.
+// It also keeps when the command loop last went around, for the /healthz endpoint, and the execution
+// metrics as of the last command or monitor tick, so scrapes don't call into libcosim.
+
+// TimingBuckets are the upper bounds, in seconds, of the buckets durations are counted in.
+var TimingBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// Timings are the number and total duration in seconds of timed events, and the number of them
+// in each of TimingBuckets, not cumulative.
+type Timings struct {
+	Count   uint64
+	Sum     float64
+	Buckets []uint64
+}
+
+type Metrics struct {
+	mutex           sync.Mutex
+	commands        map[string]*Timings
+	broadcasts      Timings
+	commandLoopTime time.Time
+	execution       ExecutionMetrics
+}
+
+// ExecutionMetrics are the execution status and size of the loaded simulation, and what the
+// webhooks watch for changes.
+type ExecutionMetrics struct {
+	Loaded                       bool
+	Status                       string
+	ConfigDir                    string
+	Time                         float64
+	TotalAverageRealTimeFactor   float64
+	RollingAverageRealTimeFactor float64
+	RealTimeFactorTarget         float64
+	RealTime                     bool
+	State                        string
+	LastErrorCode                string
+	LastErrorMessage             string
+	Slaves                       int
+	Overrides                    int
+	RunningScenario              string
+	LoadGeneration               int
+	Bookmarks                    []structs.Bookmark
+	FiredTriggers                []FiredTrigger
+}
+
+// FiredTrigger is the trigger of a trend that fired.
+type FiredTrigger struct {
+	Trend   int
+	Label   string
+	Trigger structs.Trigger
+}
+
+// ExecutionStates are the states an execution can be in.
+var ExecutionStates = []string{"COSIM_EXECUTION_STOPPED", "COSIM_EXECUTION_RUNNING", "COSIM_EXECUTION_ERROR"}
+
+func NewMetrics() *Metrics {
+	return &Metrics{commands: map[string]*Timings{}, commandLoopTime: time.Now()}
+}
+
+func (timings *Timings) observe(duration time.Duration) {
+	if timings.Buckets == nil {
+		timings.Buckets = make([]uint64, len(TimingBuckets))
+	}
+	seconds := duration.Seconds()
+	timings.Count++
+	timings.Sum += seconds
+	for i, bound := range TimingBuckets {
+		if seconds <= bound {
+			timings.Buckets[i]++
+			break
+		}
+	}
+}
+
+func (timings Timings) copy() Timings {
+	timings.Buckets = append([]uint64(nil), timings.Buckets...)
+	return timings
+}
+
+func (metrics *Metrics) observeCommand(name string, duration time.Duration) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	timings, exists := metrics.commands[name]
+	if !exists {
+		timings = &Timings{}
+		metrics.commands[name] = timings
+	}
+	timings.observe(duration)
+}
+
+func (metrics *Metrics) observeBroadcast(duration time.Duration) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.broadcasts.observe(duration)
+}
+
+func (metrics *Metrics) markCommandLoop() {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.commandLoopTime = time.Now()
+}
+
+func (metrics *Metrics) setExecution(execution ExecutionMetrics) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.execution = execution
+}
+
+// Execution returns the execution metrics cached by the command loop.
+func (metrics *Metrics) Execution() ExecutionMetrics {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	return metrics.execution
+}
+
+// CommandLoopIdle returns how long ago the command loop last finished handling a command or tick.
+func (metrics *Metrics) CommandLoopIdle() time.Duration {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	return time.Since(metrics.commandLoopTime)
+}
+
+// CommandTimings returns the timings of the commands by command name.
+func (metrics *Metrics) CommandTimings() map[string]Timings {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	timings := make(map[string]Timings, len(metrics.commands))
+	for name, t := range metrics.commands {
+		timings[name] = t.copy()
+	}
+	return timings
+}
+
+// BroadcastTimings returns the timings of the state updates sent to the clients.
+func (metrics *Metrics) BroadcastTimings() Timings {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	return metrics.broadcasts.copy()
+}
+
+// executionMetrics copies what it returns, since the cache is read outside the command loop.
+func executionMetrics(sim *Simulation, status *structs.SimulationStatus) ExecutionMetrics {
+	if !status.Loaded || sim.Execution == nil {
+		return ExecutionMetrics{Status: status.Status}
+	}
+	execStatus := getExecutionStatus(sim.Execution)
+	metrics := ExecutionMetrics{
+		Loaded:                       true,
+		Status:                       status.Status,
+		ConfigDir:                    status.ConfigDir,
+		Time:                         execStatus.time,
+		TotalAverageRealTimeFactor:   execStatus.totalAverageRealTimeFactor,
+		RollingAverageRealTimeFactor: execStatus.rollingAverageRealTimeFactor,
+		RealTimeFactorTarget:         execStatus.realTimeFactorTarget,
+		RealTime:                     execStatus.isRealTimeSimulation,
+		State:                        execStatus.state,
+		LastErrorCode:                execStatus.lastErrorCode,
+		LastErrorMessage:             execStatus.lastErrorMessage,
+		Overrides:                    len(fetchManipulatedVariables(sim.Execution)),
+		RunningScenario:              RunningScenario(sim, status),
+		LoadGeneration:               sim.loadGeneration,
+		Bookmarks:                    append([]structs.Bookmark{}, status.Bookmarks...),
+	}
+	if sim.MetaData != nil {
+		metrics.Slaves = len(sim.MetaData.FMUs)
+	}
+	for _, trend := range status.Trends {
+		if trend.Trigger != nil && trend.Trigger.Fired {
+			metrics.FiredTriggers = append(metrics.FiredTriggers, FiredTrigger{Trend: trend.Id, Label: trend.Label, Trigger: *trend.Trigger})
+		}
+	}
+	return metrics
+}
@@ -73,6 +73,13 @@ func observerGetReals(observer *C.cosim_observer, variables []structs.Variable,
 	return realSignals
 }
 
+func observerGetReal(observer *C.cosim_observer, slaveIndex int, valueRef int) float64 {
+	ref := C.cosim_value_reference(valueRef)
+	var value C.double
+	C.cosim_observer_slave_get_real(observer, C.cosim_slave_index(slaveIndex), &ref, C.size_t(1), &value)
+	return float64(value)
+}
+
 func observerGetIntegers(observer *C.cosim_observer, variables []structs.Variable, slaveIndex int) (intSignals []structs.Signal) {
 	var intValueRefs []C.cosim_value_reference
 	var intVariables []structs.Variable
@@ -163,9 +170,9 @@ func observerGetStrings(observer *C.cosim_observer, variables []structs.Variable
 	return stringSignals
 }
 
-func observerGetRealSamples(observer *C.cosim_observer, signal *structs.TrendSignal, spec structs.TrendSpec) {
-	slaveIndex := C.cosim_slave_index(signal.SlaveIndex)
-	valueRef := C.cosim_value_reference(signal.ValueReference)
+func observerRealSamples(observer *C.cosim_observer, signalSlaveIndex int, signalValueReference int, spec structs.TrendSpec) (times []float64, trendVals []float64) {
+	slaveIndex := C.cosim_slave_index(signalSlaveIndex)
+	valueRef := C.cosim_value_reference(signalValueReference)
 
 	stepNumbers := make([]C.cosim_step_number, 2)
 	var success C.int
@@ -193,14 +200,55 @@ func observerGetRealSamples(observer *C.cosim_observer, signal *structs.TrendSig
 	if ns <= 0 {
 		return
 	}
-	trendVals := make([]float64, ns)
-	times := make([]float64, ns)
+	trendVals = make([]float64, ns)
+	times = make([]float64, ns)
 	for i := 0; i < ns; i++ {
 		trendVals[i] = float64(realOutVal[i])
 		times[i] = 1e-9 * float64(timeVal[i])
 	}
-	signal.TrendXValues = times
-	signal.TrendYValues = trendVals
+	return times, trendVals
+}
+
+// observerLatestStep returns the number of the last step buffered by an observer for a simulator, or -1 if there is none.
+func observerLatestStep(observer *C.cosim_observer, slaveIndex int) int64 {
+	stepNumbers := make([]C.cosim_step_number, 2)
+	success := C.cosim_observer_get_step_numbers_for_duration(observer, C.cosim_slave_index(slaveIndex), 0, &stepNumbers[0])
+	if int(success) < 0 {
+		return -1
+	}
+	return int64(stepNumbers[1])
+}
+
+// observerSamplesFrom returns up to numSamples samples of a Real or Integer variable from a step on.
+func observerSamplesFrom(observer *C.cosim_observer, slaveIndex int, valueType string, valueReference int, fromStep int64, numSamples int) (steps []int64, times []float64, values []float64) {
+	if numSamples <= 0 {
+		return
+	}
+	index := C.cosim_slave_index(slaveIndex)
+	valueRef := C.cosim_value_reference(valueReference)
+	cnSamples := C.size_t(numSamples)
+	stepNumbers := make([]C.cosim_step_number, numSamples)
+	timeVal := make([]C.cosim_time_point, numSamples)
+	var ns int
+	switch valueType {
+	case "Real":
+		realOutVal := make([]C.double, numSamples)
+		ns = int(C.cosim_observer_slave_get_real_samples(observer, index, valueRef, C.cosim_step_number(fromStep), cnSamples, &realOutVal[0], &stepNumbers[0], &timeVal[0]))
+		for i := 0; i < ns; i++ {
+			values = append(values, float64(realOutVal[i]))
+		}
+	case "Integer":
+		intOutVal := make([]C.int, numSamples)
+		ns = int(C.cosim_observer_slave_get_integer_samples(observer, index, valueRef, C.cosim_step_number(fromStep), cnSamples, &intOutVal[0], &stepNumbers[0], &timeVal[0]))
+		for i := 0; i < ns; i++ {
+			values = append(values, float64(intOutVal[i]))
+		}
+	}
+	for i := 0; i < ns; i++ {
+		steps = append(steps, int64(stepNumbers[i]))
+		times = append(times, 1e-9*float64(timeVal[i]))
+	}
+	return steps, times, values
 }
 
 func observerGetRealSynchronizedSamples(observer *C.cosim_observer, signal1 *structs.TrendSignal, signal2 *structs.TrendSignal, spec structs.TrendSpec) {
@@ -242,6 +290,7 @@ func observerGetRealSynchronizedSamples(observer *C.cosim_observer, signal1 *str
 		trendVals2[i] = float64(realOutVal2[i])
 	}
 	signal1.TrendXValues = trendVals1
+	signal2.TrendXValues = trendVals1
 	signal2.TrendYValues = trendVals2
 }
 
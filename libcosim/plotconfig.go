@@ -0,0 +1,376 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Named plot configurations are stored as <configDir>/plots/<name>.json. The one to
+// load together with the system is given in <configDir>/PlotSettings.json, and when
+// no default is set the plain <configDir>/PlotConfig.json is used.
+const plotConfigFile = "PlotConfig.json"
+const plotConfigFolder = "plots"
+const plotSettingsFile = "PlotSettings.json"
+
+var plotConfigName = regexp.MustCompile(`^[A-Za-z0-9_\-. ]+$`)
+
+type plotSettings struct {
+	Default string `json:"default"`
+}
+
+func validatePlotConfigName(name string) error {
+	if !plotConfigName.MatchString(name) || strings.Contains(name, "..") {
+		return errors.New(strCat("Invalid plot configuration name: ", name))
+	}
+	return nil
+}
+
+// checkConfigDir rejects using plot configurations when no system is loaded, as they are kept in
+// its configuration folder.
+func checkConfigDir(status *structs.SimulationStatus) error {
+	if !status.Loaded || len(status.ConfigDir) == 0 {
+		return errors.New("No simulation is loaded, plot configurations are kept with the loaded system")
+	}
+	return nil
+}
+
+func plotConfigPath(configDir string, name string) string {
+	if len(name) == 0 {
+		return filepath.Join(configDir, plotConfigFile)
+	}
+	return filepath.Join(configDir, plotConfigFolder, name+".json")
+}
+
+func readPlotSettings(configDir string) (settings plotSettings) {
+	bytes, err := ioutil.ReadFile(filepath.Join(configDir, plotSettingsFile))
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bytes, &settings)
+	if err != nil {
+		log.Println("Can't unmarshal", plotSettingsFile, "contents:", err.Error())
+	}
+	return
+}
+
+func defaultPlotConfigPath(configDir string) string {
+	settings := readPlotSettings(configDir)
+	if len(settings.Default) > 0 {
+		pathToFile := plotConfigPath(configDir, settings.Default)
+		if doesFileExist(pathToFile) {
+			return pathToFile
+		}
+		log.Println("Default plot configuration does not exist:", pathToFile)
+	}
+	return plotConfigPath(configDir, "")
+}
+
+func createPlotConfig(status *structs.SimulationStatus) structs.PlotConfig {
+	plots := []structs.Plot{}
+	for _, trend := range status.Trends {
+		variables := []structs.PlotVariable{}
+		for _, trendSignal := range trend.TrendSignals {
+			variables = append(variables, structs.PlotVariable{
+				Simulator: trendSignal.Module,
+				Variable:  trendSignal.Signal,
+				Axis:      trendSignal.Axis,
+				YAxis:     trendSignal.YAxis,
+				Scale:     trendSignal.Scale,
+				Offset:    trendSignal.Offset,
+				Color:     trendSignal.Color,
+				Unit:      trendSignal.Unit,
+			})
+		}
+		variables = append(variables, trend.UnsupportedVariables...)
+		plots = append(plots, structs.Plot{
+			Label:         trend.Label,
+			PlotType:      trend.PlotType,
+			PlotVariables: variables,
+		})
+	}
+	return structs.PlotConfig{
+		Plots:          plots,
+		DerivedSignals: status.DerivedSignals,
+	}
+}
+
+// The exported plot configuration functions are called by the server outside the command loop, so
+// they take the simulation lock, the write lock for the ones changing files.
+
+// SavePlotConfig writes the current trends to the named plot configuration,
+// or to PlotConfig.json when no name is given.
+func SavePlotConfig(sim *Simulation, status *structs.SimulationStatus, name string) (bool, string) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	return savePlotConfig(status, name)
+}
+
+func savePlotConfig(status *structs.SimulationStatus, name string) (bool, string) {
+	if err := checkConfigDir(status); err != nil {
+		return false, err.Error()
+	}
+	if len(name) > 0 {
+		if err := validatePlotConfigName(name); err != nil {
+			return false, err.Error()
+		}
+		if err := os.MkdirAll(filepath.Join(status.ConfigDir, plotConfigFolder), 0755); err != nil {
+			return false, strCat("Could not create plot configuration folder: ", err.Error())
+		}
+	}
+	pathToFile := plotConfigPath(status.ConfigDir, name)
+	plotConfig := createPlotConfig(status)
+	plotConfigJson, _ := json.Marshal(plotConfig)
+	err := ioutil.WriteFile(pathToFile, plotConfigJson, 0644)
+	if err != nil {
+		log.Println("Could not write PlotConfig to file, data: ", plotConfig, ", error was:", err)
+		return false, strCat("Could not write plot configuration to ", pathToFile, ": ", err.Error())
+	}
+	return true, strCat("Wrote plot configuration to ", pathToFile)
+}
+
+func ListPlotConfigs(sim *Simulation, status *structs.SimulationStatus) structs.PlotConfigs {
+	sim.lock.RLock()
+	defer sim.lock.RUnlock()
+	return listPlotConfigs(status)
+}
+
+func listPlotConfigs(status *structs.SimulationStatus) structs.PlotConfigs {
+	configs := structs.PlotConfigs{Names: []string{}}
+	if checkConfigDir(status) != nil {
+		return configs
+	}
+	configs.Default = readPlotSettings(status.ConfigDir).Default
+	files, err := ioutil.ReadDir(filepath.Join(status.ConfigDir, plotConfigFolder))
+	if err != nil {
+		return configs
+	}
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".json") {
+			configs.Names = append(configs.Names, strings.TrimSuffix(f.Name(), ".json"))
+		}
+	}
+	sort.Strings(configs.Names)
+	return configs
+}
+
+func DeletePlotConfig(sim *Simulation, status *structs.SimulationStatus, name string) (bool, string) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	return deletePlotConfig(status, name)
+}
+
+func deletePlotConfig(status *structs.SimulationStatus, name string) (bool, string) {
+	if err := checkConfigDir(status); err != nil {
+		return false, err.Error()
+	}
+	if err := validatePlotConfigName(name); err != nil {
+		return false, err.Error()
+	}
+	pathToFile := plotConfigPath(status.ConfigDir, name)
+	if err := os.Remove(pathToFile); err != nil {
+		return false, strCat("Could not delete plot configuration: ", err.Error())
+	}
+	if readPlotSettings(status.ConfigDir).Default == name {
+		setDefaultPlotConfig(status, "")
+	}
+	return true, strCat("Deleted plot configuration ", pathToFile)
+}
+
+// SetDefaultPlotConfig chooses the plot configuration loaded together with the system.
+// An empty name falls back to PlotConfig.json.
+func SetDefaultPlotConfig(sim *Simulation, status *structs.SimulationStatus, name string) (bool, string) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	return setDefaultPlotConfig(status, name)
+}
+
+func setDefaultPlotConfig(status *structs.SimulationStatus, name string) (bool, string) {
+	if err := checkConfigDir(status); err != nil {
+		return false, err.Error()
+	}
+	if len(name) > 0 {
+		if err := validatePlotConfigName(name); err != nil {
+			return false, err.Error()
+		}
+		if !doesFileExist(plotConfigPath(status.ConfigDir, name)) {
+			return false, strCat("Plot configuration ", name, " does not exist")
+		}
+	}
+	settingsJson, _ := json.Marshal(plotSettings{Default: name})
+	err := ioutil.WriteFile(filepath.Join(status.ConfigDir, plotSettingsFile), settingsJson, 0644)
+	if err != nil {
+		return false, strCat("Could not write plot settings: ", err.Error())
+	}
+	if len(name) == 0 {
+		return true, strCat("Plots will be loaded from ", plotConfigFile)
+	}
+	return true, strCat("Plot configuration ", name, " will be loaded by default")
+}
+
+// checkPlotVariable records in the report why a plot variable can't be added to a trend, if it can't.
+func checkPlotVariable(sim *Simulation, status *structs.SimulationStatus, variable structs.PlotVariable, report *structs.PlotConfigReport) bool {
+	if _, err := findDerivedSignal(status, variable.Simulator, variable.Variable); err == nil {
+		return true
+	}
+	fmu, err := findFmu(sim.MetaData, variable.Simulator)
+	if err != nil {
+		for _, missing := range report.MissingSimulators {
+			if missing == variable.Simulator {
+				return false
+			}
+		}
+		report.MissingSimulators = append(report.MissingSimulators, variable.Simulator)
+		return false
+	}
+	found, err := findVariable(fmu, variable.Variable)
+	if err != nil {
+		report.MissingVariables = append(report.MissingVariables, variable)
+		return false
+	}
+	if found.Type != "Real" {
+		report.UnsupportedTypes = append(report.UnsupportedTypes, variable)
+		return false
+	}
+	return true
+}
+
+func applySignalSettings(trendSignal *structs.TrendSignal, variable structs.PlotVariable) {
+	trendSignal.YAxis = variable.YAxis
+	trendSignal.Scale = variable.Scale
+	trendSignal.Offset = variable.Offset
+	trendSignal.Color = variable.Color
+	trendSignal.Unit = variable.Unit
+}
+
+func applyPlotConfig(sim *Simulation, status *structs.SimulationStatus, plotConfig structs.PlotConfig, report *structs.PlotConfigReport) {
+	for _, derived := range plotConfig.DerivedSignals {
+		success, message := addDerivedSignal(sim, status, strCat(derived.Module, ".", derived.Name), derived.Expression)
+		if !success {
+			log.Println("Could not add derived signal:", message)
+			report.Errors = append(report.Errors, message)
+		}
+	}
+
+	for _, plot := range plotConfig.Plots {
+		success, message, id := addNewTrend(status, plot.PlotType, plot.Label)
+		if !success {
+			log.Println("Could not add new plot:", message)
+			report.Errors = append(report.Errors, message)
+			continue
+		}
+		trendIdx := len(status.Trends) - 1
+		trendId := strconv.Itoa(id)
+		for _, variable := range plot.PlotVariables {
+			unsupported := len(report.UnsupportedTypes)
+			if !checkPlotVariable(sim, status, variable, report) {
+				log.Println("Could not add variable to plot:", variable.Simulator, variable.Variable)
+				if len(report.UnsupportedTypes) > unsupported {
+					// Kept so that saving the plots again doesn't lose them.
+					status.Trends[trendIdx].UnsupportedVariables = append(status.Trends[trendIdx].UnsupportedVariables, variable)
+				}
+				continue
+			}
+			success, message := addToTrend(sim, status, variable.Simulator, variable.Variable, trendId, variable.Axis)
+			if !success {
+				log.Println("Could not add variable to plot:", message)
+				report.Errors = append(report.Errors, message)
+				continue
+			}
+			trendSignals := status.Trends[trendIdx].TrendSignals
+			applySignalSettings(&trendSignals[len(trendSignals)-1], variable)
+		}
+	}
+}
+
+// reportSummary describes the problems found while importing a plot configuration, if any.
+func reportSummary(report *structs.PlotConfigReport) string {
+	var problems []string
+	if n := len(report.MissingSimulators); n > 0 {
+		problems = append(problems, strCat(strconv.Itoa(n), " missing simulator(s)"))
+	}
+	if n := len(report.MissingVariables); n > 0 {
+		problems = append(problems, strCat(strconv.Itoa(n), " missing variable(s)"))
+	}
+	if n := len(report.UnsupportedTypes); n > 0 {
+		problems = append(problems, strCat(strconv.Itoa(n), " variable(s) of unsupported type"))
+	}
+	if n := len(report.Errors); n > 0 {
+		problems = append(problems, strCat(strconv.Itoa(n), " other error(s)"))
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+	return strCat("Plot configuration ", report.File, " was partially imported: ", strings.Join(problems, ", "))
+}
+
+func clearTrends(sim *Simulation, status *structs.SimulationStatus) {
+	for _, trend := range status.Trends {
+		for _, trendSignal := range trend.TrendSignals {
+			if err := stopObservingTrendSignal(sim, status, trendSignal); err != nil {
+				log.Println("Cannot stop observing", err)
+			}
+		}
+	}
+	status.Trends = []structs.Trend{}
+	sim.triggerScans = nil
+}
+
+// replaceDerivedSignals removes the derived signals that a plot configuration defines anew, and
+// keeps the others.
+func replaceDerivedSignals(sim *Simulation, status *structs.SimulationStatus, replacements []structs.DerivedSignal) {
+	var kept []structs.DerivedSignal
+	for _, derived := range status.DerivedSignals {
+		replaced := false
+		for _, replacement := range replacements {
+			if replacement.Module == derived.Module && replacement.Name == derived.Name {
+				replaced = true
+				break
+			}
+		}
+		if replaced {
+			delete(sim.derivedSignals, derived)
+		} else {
+			kept = append(kept, derived)
+		}
+	}
+	status.DerivedSignals = kept
+}
+
+// loadPlotConfig replaces the trends of the running simulation with the named plot configuration.
+func loadPlotConfig(sim *Simulation, status *structs.SimulationStatus, name string) (bool, string, *structs.PlotConfigReport) {
+	if err := checkConfigDir(status); err != nil {
+		return false, err.Error(), nil
+	}
+	if len(name) > 0 {
+		if err := validatePlotConfigName(name); err != nil {
+			return false, err.Error(), nil
+		}
+	}
+	pathToFile := plotConfigPath(status.ConfigDir, name)
+	plotConfig, err := parsePlotConfig(pathToFile)
+	if err != nil {
+		return false, strCat("Could not read plot configuration: ", err.Error()), nil
+	}
+
+	clearTrends(sim, status)
+	replaceDerivedSignals(sim, status, plotConfig.DerivedSignals)
+	report := &structs.PlotConfigReport{File: pathToFile}
+	applyPlotConfig(sim, status, plotConfig, report)
+	if summary := reportSummary(report); len(summary) > 0 {
+		return true, summary, report
+	}
+	return true, strCat("Loaded plot configuration from ", pathToFile), report
+}
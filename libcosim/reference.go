@@ -0,0 +1,218 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"encoding/csv"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A reference run is a previously recorded run loaded from file observer CSV output or from an
+// exported trend. Trend signals with the reference overlay enabled get the reference curve in
+// the time window of the live samples, and the difference between the live and the reference values.
+
+type referenceSeries struct {
+	times  []float64
+	values []float64
+}
+
+var fileObserverTimestamp = regexp.MustCompile(`_\d{8}_\d{6}_\d{3}$`)
+var columnDescription = regexp.MustCompile(`\s*\[.*\]$`)
+
+// referenceModule guesses the simulator name from a file observer CSV file name like Engine_20200101_120000_000.csv.
+func referenceModule(pathToFile string) string {
+	name := strings.TrimSuffix(filepath.Base(pathToFile), filepath.Ext(pathToFile))
+	return fileObserverTimestamp.ReplaceAllString(name, "")
+}
+
+// parseReferenceCsv reads the Real columns of a CSV file. Files written by the file observer have a
+// StepCount column and hold the variables of one simulator, whose name is given or taken from the file name.
+// Exported trends have columns named Module.signal.
+func parseReferenceCsv(pathToFile string, module string, series map[string]referenceSeries) error {
+	file, err := os.Open(pathToFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) < 2 || len(records[0]) < 2 || !strings.EqualFold(strings.TrimSpace(records[0][0]), "Time") {
+		return errors.New(strCat(pathToFile, " does not have a Time column followed by signal columns"))
+	}
+
+	header := records[0]
+	fileObserverFormat := false
+	for _, column := range header {
+		if strings.TrimSpace(column) == "StepCount" {
+			fileObserverFormat = true
+		}
+	}
+	if fileObserverFormat && len(module) == 0 {
+		module = referenceModule(pathToFile)
+	}
+
+	times := make([]float64, 0, len(records)-1)
+	for _, record := range records[1:] {
+		t, err := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		if err != nil {
+			return errors.New(strCat("Cannot parse time in ", pathToFile, ": ", record[0]))
+		}
+		times = append(times, t)
+	}
+
+	for column := 1; column < len(header); column++ {
+		name := columnDescription.ReplaceAllString(strings.TrimSpace(header[column]), "")
+		if name == "StepCount" || name == "Bookmarks" {
+			continue
+		}
+		var key string
+		if fileObserverFormat {
+			key = strCat(module, ".", name)
+		} else if strings.Contains(name, ".") {
+			key = name
+		} else {
+			continue
+		}
+
+		values := make([]float64, 0, len(times))
+		for _, record := range records[1:] {
+			if column >= len(record) {
+				break
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(record[column]), 64)
+			if err != nil {
+				break
+			}
+			values = append(values, value)
+		}
+		if len(values) == len(times) {
+			series[key] = referenceSeries{times: times, values: values}
+		}
+	}
+	return nil
+}
+
+// loadReference loads a reference run from a CSV file, or from all CSV files in a folder, within
+// the configuration or log folder. Relative paths are relative to the configuration folder.
+func loadReference(sim *Simulation, status *structs.SimulationStatus, path string, module string) (bool, string) {
+	if !filepath.IsAbs(path) && len(status.ConfigDir) > 0 {
+		path = filepath.Join(status.ConfigDir, path)
+	}
+	if !(len(status.ConfigDir) > 0 && WithinFolder(status.ConfigDir, path)) && !(len(status.LogDir) > 0 && WithinFolder(status.LogDir, path)) {
+		return false, "Reference runs can only be loaded from within the configuration or log folder"
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, strCat("Can't find reference run ", path)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			return false, strCat("Could not read reference run folder: ", err.Error())
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".csv") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		// Folders hold one file per simulator, so the simulator name is taken from each file name.
+		module = ""
+	}
+
+	series := map[string]referenceSeries{}
+	for _, file := range files {
+		if err := parseReferenceCsv(file, module, series); err != nil {
+			return false, strCat("Could not read reference run: ", err.Error())
+		}
+	}
+	if len(series) == 0 {
+		return false, strCat("No Real signals found in reference run ", path)
+	}
+
+	reference := &structs.Reference{Path: path}
+	for name := range series {
+		reference.Signals = append(reference.Signals, name)
+	}
+	sort.Strings(reference.Signals)
+	sim.reference = series
+	status.Reference = reference
+	return true, strCat("Loaded reference run with ", strconv.Itoa(len(series)), " signals from ", path)
+}
+
+func clearReference(sim *Simulation, status *structs.SimulationStatus) (bool, string) {
+	sim.reference = nil
+	status.Reference = nil
+	return true, "Removed reference run"
+}
+
+func setReferenceOverlay(status *structs.SimulationStatus, trendId string, module string, signal string, enabled string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+	for i, trendSignal := range status.Trends[idx].TrendSignals {
+		if trendSignal.Module == module && trendSignal.Signal == signal {
+			status.Trends[idx].TrendSignals[i].Reference = enabled == "true"
+			if enabled == "true" {
+				return true, strCat("Showing reference run for ", module, ".", signal)
+			}
+			return true, strCat("Hiding reference run for ", module, ".", signal)
+		}
+	}
+	return false, strCat("Variable ", module, ".", signal, " is not in trend ", trendId)
+}
+
+// clearReferenceOverlay removes the reference values of a trend signal.
+func clearReferenceOverlay(signal *structs.TrendSignal) {
+	signal.ReferenceXValues = nil
+	signal.ReferenceYValues = nil
+	signal.DiffYValues = nil
+}
+
+// referenceOverlay sets the reference values in the time window of the live values of a trend signal,
+// and the difference between the live and the reference values at the live time points.
+// Both are scaled like the live values.
+func referenceOverlay(sim *Simulation, signal *structs.TrendSignal) {
+	clearReferenceOverlay(signal)
+	if !signal.Reference || len(signal.TrendXValues) == 0 {
+		return
+	}
+	series, exists := sim.reference[strCat(signal.Module, ".", signal.Signal)]
+	if !exists || len(series.times) == 0 {
+		return
+	}
+
+	begin := signal.TrendXValues[0]
+	end := signal.TrendXValues[len(signal.TrendXValues)-1]
+	first := sort.SearchFloat64s(series.times, begin)
+	last := sort.Search(len(series.times), func(i int) bool {
+		return series.times[i] > end
+	})
+	signal.ReferenceXValues = series.times[first:last]
+	signal.ReferenceYValues = scaleValues(series.values[first:last], signal.Scale, signal.Offset)
+
+	aligned := scaleValues(alignSamples(signal.TrendXValues, series.times, series.values), signal.Scale, signal.Offset)
+	signal.DiffYValues = make([]float64, len(aligned))
+	for i, value := range signal.TrendYValues {
+		signal.DiffYValues[i] = value - aligned[i]
+	}
+}
@@ -0,0 +1,51 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestReferenceModule(t *testing.T) {
+	tests := map[string]string{
+		"Engine_20200101_120000_000.csv":          "Engine",
+		"logs/Thruster_1_20200101_120000_000.csv": "Thruster_1",
+		"Thruster_2_20200101_120000_000.csv":      "Thruster_2",
+		"Pump_v2.csv":                             "Pump_v2",
+		"Engine.csv":                              "Engine",
+	}
+	for file, module := range tests {
+		if got := referenceModule(file); got != module {
+			t.Errorf("referenceModule(%q) = %q, want %q", file, got, module)
+		}
+	}
+}
+
+func TestLoadReferenceWithinFolders(t *testing.T) {
+	configDir, logDir, elsewhere := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, dir := range []string{configDir, logDir, elsewhere} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "Engine.csv"), []byte("Time,StepCount,speed\n0,0,1\n1,1,2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		path   string
+		loaded bool
+	}{
+		{"Engine.csv", true},
+		{filepath.Join(logDir, "Engine.csv"), true},
+		{filepath.Join(elsewhere, "Engine.csv"), false},
+		{filepath.Join("..", filepath.Base(elsewhere), "Engine.csv"), false},
+	}
+	for _, test := range tests {
+		status := &structs.SimulationStatus{ConfigDir: configDir, LogDir: logDir}
+		if loaded, message := loadReference(&Simulation{}, status, test.path, ""); loaded != test.loaded {
+			t.Errorf("loadReference(%s) = %v, %s, want %v", test.path, loaded, message, test.loaded)
+		}
+	}
+}
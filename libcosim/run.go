@@ -0,0 +1,300 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A recorded run is the CSV files the file observer wrote for one simulation, one per simulator,
+// named after the simulator and the time the file was created, like Engine_20200101_120000_000.csv.
+// A log folder holds the runs of every simulation that logged to it, so its files are grouped into
+// runs by their timestamps. When a run is open, its variables can be added to trends like the
+// variables of a loaded simulation, also when no simulation is loaded. Simulators of a loaded
+// simulation take precedence over recorded ones with the same name.
+
+const recordedCausality = "recorded"
+
+// runFileWindow is how far apart the files of one run may be created.
+const runFileWindow = 10 * time.Second
+
+const runTimestampLayout = "20060102_150405.000"
+
+type recordedRun struct {
+	id      string
+	started time.Time
+	files   []string
+	modules []string
+}
+
+type runFile struct {
+	name    string
+	module  string
+	stamp   string
+	created time.Time
+}
+
+// parseRunTimestamp parses a file observer timestamp like 20200101_120000_000.
+func parseRunTimestamp(stamp string) (time.Time, error) {
+	if len(stamp) < 4 {
+		return time.Time{}, errors.New(strCat("Invalid run timestamp: ", stamp))
+	}
+	return time.ParseInLocation(runTimestampLayout, stamp[:len(stamp)-4]+"."+stamp[len(stamp)-3:], time.Local)
+}
+
+// formatRunTimestamp formats a time like the file observer timestamps.
+func formatRunTimestamp(t time.Time) string {
+	return strings.Replace(t.Format(runTimestampLayout), ".", "_", 1)
+}
+
+// groupRunFiles groups the CSV files of a log folder into runs, oldest first. A run is identified by
+// the timestamp of its first file. Files without a timestamp make up a run of their own, with an
+// empty id.
+func groupRunFiles(dir string) ([]recordedRun, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []runFile
+	var unstamped recordedRun
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".csv") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		stamp := strings.TrimPrefix(fileObserverTimestamp.FindString(name), "_")
+		created, err := parseRunTimestamp(stamp)
+		if err != nil {
+			unstamped.files = append(unstamped.files, entry.Name())
+			if entry.ModTime().After(unstamped.started) {
+				unstamped.started = entry.ModTime()
+			}
+			continue
+		}
+		files = append(files, runFile{name: entry.Name(), module: referenceModule(entry.Name()), stamp: stamp, created: created})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].created.Before(files[j].created)
+	})
+
+	var runs []recordedRun
+	for _, file := range files {
+		current := len(runs) - 1
+		if current < 0 || file.created.Sub(runs[current].started) > runFileWindow || containsString(runs[current].modules, file.module) {
+			runs = append(runs, recordedRun{id: file.stamp, started: file.created})
+			current++
+		}
+		runs[current].files = append(runs[current].files, file.name)
+		runs[current].modules = append(runs[current].modules, file.module)
+	}
+	if len(unstamped.files) > 0 {
+		runs = append([]recordedRun{unstamped}, runs...)
+	}
+	return runs, nil
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// ListRuns returns the runs in root and its sub folders, most recent first.
+func ListRuns(root string) ([]structs.RunInfo, error) {
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	runs := []structs.RunInfo{}
+	addRuns := func(path string, name string) {
+		groups, err := groupRunFiles(path)
+		if err != nil {
+			return
+		}
+		for _, group := range groups {
+			runs = append(runs, structs.RunInfo{
+				Name:    name,
+				Path:    path,
+				Id:      group.id,
+				Started: group.started.Format(time.RFC3339),
+				Files:   len(group.files),
+				Modules: group.modules,
+			})
+		}
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			addRuns(filepath.Join(root, entry.Name()), entry.Name())
+		}
+	}
+	addRuns(root, filepath.Base(root))
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Started > runs[j].Started
+	})
+	return runs, nil
+}
+
+// RunsRoot is the folder runs are listed from: the log folder of the simulation.
+func RunsRoot(sim *Simulation, status *structs.SimulationStatus) string {
+	sim.lock.RLock()
+	defer sim.lock.RUnlock()
+	return status.LogDir
+}
+
+func runModules(series map[string]referenceSeries) structs.MetaData {
+	modules := map[string]*structs.FMU{}
+	var names []string
+	for key := range series {
+		module, variable := splitDerivedName(key)
+		fmu, exists := modules[module]
+		if !exists {
+			fmu = &structs.FMU{Name: module, ExecutionIndex: -1}
+			modules[module] = fmu
+			names = append(names, module)
+		}
+		fmu.Variables = append(fmu.Variables, structs.Variable{
+			Name:           variable,
+			ValueReference: -1,
+			Causality:      recordedCausality,
+			Type:           "Real",
+		})
+	}
+	sort.Strings(names)
+	metaData := structs.MetaData{}
+	for _, name := range names {
+		fmu := modules[name]
+		sort.Slice(fmu.Variables, func(i, j int) bool {
+			return fmu.Variables[i].Name < fmu.Variables[j].Name
+		})
+		metaData.FMUs = append(metaData.FMUs, *fmu)
+	}
+	return metaData
+}
+
+// openRun reads the CSV files of one run in a log folder written by the file observer. Without an
+// id the most recent run is opened. Like the listing, it is confined to the known log folder, and
+// relative paths are relative to it.
+func openRun(sim *Simulation, status *structs.SimulationStatus, path string, id string) (bool, string) {
+	if len(status.LogDir) == 0 {
+		return false, "No log folder is known, load a simulation with a log folder first"
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(status.LogDir, path)
+	}
+	if !WithinFolder(status.LogDir, path) {
+		return false, strCat("Runs can only be opened within the log folder ", status.LogDir)
+	}
+	runs, err := groupRunFiles(path)
+	if err != nil || len(runs) == 0 {
+		return false, strCat("No CSV files found in ", path)
+	}
+	run := runs[len(runs)-1]
+	if len(id) > 0 {
+		found := false
+		for _, r := range runs {
+			if r.id == id {
+				run, found = r, true
+			}
+		}
+		if !found {
+			return false, strCat("There is no run ", id, " in ", path)
+		}
+	}
+	series := map[string]referenceSeries{}
+	for _, file := range run.files {
+		if err := parseReferenceCsv(filepath.Join(path, file), "", series); err != nil {
+			return false, strCat("Could not read run: ", err.Error())
+		}
+	}
+	if len(series) == 0 {
+		return false, strCat("No Real variables found in run ", path)
+	}
+	closeRun(sim, status)
+	sim.run = series
+	status.Run = &structs.Run{Path: path, Id: run.id, Modules: runModules(series), Bookmarks: readRunBookmarks(path, run)}
+	if len(runs) > 1 && len(id) == 0 {
+		return true, strCat("Opened the most recent of ", strconv.Itoa(len(runs)), " runs in ", path)
+	}
+	return true, strCat("Opened run ", path)
+}
+
+// closeRun closes the open run and removes its variables, and the triggers on them, from the trends.
+func closeRun(sim *Simulation, status *structs.SimulationStatus) (bool, string) {
+	if status.Run == nil {
+		return false, "No run is open"
+	}
+	for i := range status.Trends {
+		var kept []structs.TrendSignal
+		for _, trendSignal := range status.Trends[i].TrendSignals {
+			if trendSignal.Causality != recordedCausality {
+				kept = append(kept, trendSignal)
+			}
+		}
+		status.Trends[i].TrendSignals = append([]structs.TrendSignal{}, kept...)
+		dropOrphanedTrigger(sim, &status.Trends[i])
+	}
+	message := strCat("Closed run ", status.Run.Path)
+	sim.run = nil
+	status.Run = nil
+	return true, message
+}
+
+// recordedTrendSignal returns a trend signal for a variable of the open run, unless a
+// simulator with the same name is loaded.
+func recordedTrendSignal(sim *Simulation, module string, signal string) (structs.TrendSignal, error) {
+	if sim.MetaData != nil {
+		if _, err := findFmu(sim.MetaData, module); err == nil {
+			return structs.TrendSignal{}, errors.New(strCat("Simulator ", module, " is loaded"))
+		}
+	}
+	if _, exists := sim.run[strCat(module, ".", signal)]; !exists {
+		return structs.TrendSignal{}, errors.New(strCat("Variable ", module, ".", signal, " is not in the open run"))
+	}
+	return structs.TrendSignal{
+		Module:         module,
+		SlaveIndex:     -1,
+		Signal:         signal,
+		Causality:      recordedCausality,
+		Type:           "Real",
+		ValueReference: -1,
+	}, nil
+}
+
+// recordedRealSamples returns at most historyMaxPoints samples of a recorded variable for the trend spec.
+// An automatic range is counted back from the end of the run.
+func recordedRealSamples(sim *Simulation, signal *structs.TrendSignal, spec structs.TrendSpec) (times []float64, values []float64) {
+	series, exists := sim.run[strCat(signal.Module, ".", signal.Signal)]
+	if !exists || len(series.times) == 0 {
+		return
+	}
+	begin, end := spec.Begin, spec.End
+	if spec.Auto {
+		end = series.times[len(series.times)-1]
+		begin = end - spec.Range
+	}
+	first := sort.SearchFloat64s(series.times, begin)
+	last := sort.Search(len(series.times), func(i int) bool {
+		return series.times[i] > end
+	})
+	stride := (last - first + historyMaxPoints - 1) / historyMaxPoints
+	if stride <= 1 {
+		return series.times[first:last], series.values[first:last]
+	}
+	for i := first; i < last; i += stride {
+		times = append(times, series.times[i])
+		values = append(values, series.values[i])
+	}
+	return times, values
+}
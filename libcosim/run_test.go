@@ -0,0 +1,123 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGroupRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"Engine_20200101_120000_000.csv",
+		"Thruster_1_20200101_120000_004.csv",
+		"Thruster_2_20200101_120001_210.csv",
+		"Engine_20200101_130000_000.csv",
+		"Thruster_1_20200101_130000_002.csv",
+		"Engine_20200101_130003_000.csv",
+		"trend-export.csv",
+		"notes.txt",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("Time,StepCount\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := groupRunFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	var modules [][]string
+	for _, run := range runs {
+		ids = append(ids, run.id)
+		modules = append(modules, run.modules)
+	}
+	wantIds := []string{"", "20200101_120000_000", "20200101_130000_000", "20200101_130003_000"}
+	wantModules := [][]string{nil, {"Engine", "Thruster_1", "Thruster_2"}, {"Engine", "Thruster_1"}, {"Engine"}}
+	if !reflect.DeepEqual(ids, wantIds) {
+		t.Errorf("got runs %v, want %v", ids, wantIds)
+	}
+	if !reflect.DeepEqual(modules, wantModules) {
+		t.Errorf("got modules %v, want %v", modules, wantModules)
+	}
+	if !reflect.DeepEqual(runs[0].files, []string{"trend-export.csv"}) {
+		t.Errorf("got files %v without timestamp, want trend-export.csv", runs[0].files)
+	}
+}
+
+func TestReadRunBookmarks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"bookmarks_20200101_115959_500.json": `[{"id": 1, "time": 2, "label": "first run"}]`,
+		"bookmarks_20200101_130002_000.json": `[{"id": 2, "time": 1, "label": "second run"}]`,
+	}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		stamp string
+		label string
+	}{
+		{"20200101_120000_000", "first run"},
+		{"20200101_130000_000", "second run"},
+		{"20200101_130003_000", "second run"},
+		{"20200101_140000_000", ""},
+	}
+	for _, test := range tests {
+		started, err := parseRunTimestamp(test.stamp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bookmarks := readRunBookmarks(dir, recordedRun{id: test.stamp, started: started})
+		var label string
+		if len(bookmarks) > 0 {
+			label = bookmarks[0].Label
+		}
+		if label != test.label {
+			t.Errorf("got bookmark %q for run %s, want %q", label, test.stamp, test.label)
+		}
+	}
+	if formatted := formatRunTimestamp(time.Date(2020, 1, 1, 12, 0, 0, 4e6, time.Local)); formatted != "20200101_120000_004" {
+		t.Errorf("got timestamp %s, want 20200101_120000_004", formatted)
+	}
+}
+
+func TestOpenRunWithinLogFolder(t *testing.T) {
+	logDir, elsewhere := t.TempDir(), t.TempDir()
+	for _, dir := range []string{logDir, elsewhere} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "Engine_20200101_120000_000.csv"), []byte("Time,StepCount,speed\n0,0,1\n1,1,2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := &structs.SimulationStatus{LogDir: logDir}
+	if opened, message := openRun(&Simulation{}, status, elsewhere, ""); opened {
+		t.Errorf("opened run outside the log folder: %s", message)
+	}
+	if opened, message := openRun(&Simulation{}, &structs.SimulationStatus{}, logDir, ""); opened {
+		t.Errorf("opened run without a log folder: %s", message)
+	}
+	sim := &Simulation{}
+	if opened, message := openRun(sim, status, ".", ""); !opened {
+		t.Fatalf("could not open run in the log folder: %s", message)
+	}
+
+	status.Trends = []structs.Trend{{
+		Id:           1,
+		TrendSignals: []structs.TrendSignal{{Module: "Engine", Signal: "speed", Causality: recordedCausality, Type: "Real"}},
+		Trigger:      &structs.Trigger{Module: "Engine", Signal: "speed", Armed: true},
+	}}
+	closeRun(sim, status)
+	if status.Trends[0].Trigger != nil {
+		t.Errorf("got trigger %+v on a signal of the closed run, want none", status.Trends[0].Trigger)
+	}
+}
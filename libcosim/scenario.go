@@ -57,6 +57,14 @@ func loadScenario(sim *Simulation, status *structs.SimulationStatus, filename st
 	return true, strCat("Successfully loaded scenario ", pathToFile)
 }
 
+// RunningScenario returns the file name of the scenario that is running, if any.
+func RunningScenario(sim *Simulation, status *structs.SimulationStatus) string {
+	if status.Loaded && sim.ScenarioManager != nil && isScenarioRunning(sim.ScenarioManager) {
+		return status.CurrentScenario
+	}
+	return ""
+}
+
 func abortScenario(manipulator *C.cosim_manipulator) (bool, string) {
 	intVal := C.cosim_scenario_abort(manipulator)
 	if int(intVal) < 0 {
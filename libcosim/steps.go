@@ -0,0 +1,212 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"log"
+	"sort"
+	"time"
+)
+
+// A step stream passes the values of some variables at every step of the simulation to a channel,
+// for the servers that send them elsewhere. The command loop reads the steps taken since its last
+// tick from the trend observer, so none is missed as long as the observer still buffers them. The
+// observer only keeps Real and Integer variables per step, the other variables and derived signals
+// get the value they have when the command loop reads the steps.
+
+const (
+	stepStreamInterval = 10 * time.Millisecond
+	stepStreamBuffer   = 1000
+	stepStreamMaxSteps = 10000
+)
+
+// StepSample holds the values of the variables of a step stream at a step, by module and variable name joined with a dot.
+type StepSample struct {
+	Time   float64
+	Values map[string]interface{}
+}
+
+type streamedVariable struct {
+	name       string
+	slaveIndex int
+	variable   structs.Variable
+}
+
+type StepStream struct {
+	Samples   chan StepSample
+	variables []streamedVariable
+	others    []structs.WatchedVariable
+	nextStep  int64
+	lastTime  float64
+	last      map[string]interface{}
+	dropped   int
+}
+
+func observesPerStep(variable structs.Variable) bool {
+	return variable.Type == "Real" || variable.Type == "Integer"
+}
+
+// streamObserves tells whether a step stream other than except observes a variable in the trend observer.
+func streamObserves(sim *Simulation, variable observedVariable, except *StepStream) bool {
+	for _, stream := range sim.stepStreams {
+		if stream == except {
+			continue
+		}
+		for _, streamed := range stream.variables {
+			if (observedVariable{streamed.slaveIndex, streamed.variable.Type, streamed.variable.ValueReference}) == variable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// StreamSteps starts a step stream of the variables given as module and variable name pairs, from the next step on.
+func StreamSteps(sim *Simulation, status *structs.SimulationStatus, pairs []string) (*StepStream, error) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	if !status.Loaded {
+		return nil, ErrNotLoaded
+	}
+	stream := &StepStream{Samples: make(chan StepSample, stepStreamBuffer), nextStep: -1, lastTime: -1, last: map[string]interface{}{}}
+	for j := 0; j+1 < len(pairs); j += 2 {
+		watched, err := resolveWatchedVariable(sim, status, pairs[j], pairs[j+1])
+		if err != nil {
+			return nil, err
+		}
+		fmu, err := findFmu(sim.MetaData, watched.Module)
+		if err != nil || !observesPerStep(watched.Variable) {
+			stream.others = append(stream.others, watched)
+			continue
+		}
+		stream.variables = append(stream.variables, streamedVariable{
+			name:       strCat(watched.Module, ".", watched.Variable.Name),
+			slaveIndex: fmu.ExecutionIndex,
+			variable:   watched.Variable,
+		})
+	}
+	for _, streamed := range stream.variables {
+		variable := observedVariable{streamed.slaveIndex, streamed.variable.Type, streamed.variable.ValueReference}
+		if sim.trendObservations[variable] == 0 && !streamObserves(sim, variable, nil) {
+			observerStartObserving(sim.TrendObserver, streamed.slaveIndex, streamed.variable.Type, streamed.variable.ValueReference)
+		}
+		if latest := observerLatestStep(sim.TrendObserver, streamed.slaveIndex); latest+1 > stream.nextStep {
+			stream.nextStep = latest + 1
+		}
+	}
+	sim.stepStreams = append(sim.stepStreams, stream)
+	return stream, nil
+}
+
+// StopStepStream stops a step stream and closes its channel, unless the simulation was torn down already.
+func StopStepStream(sim *Simulation, stream *StepStream) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	for i, other := range sim.stepStreams {
+		if other != stream {
+			continue
+		}
+		for _, streamed := range stream.variables {
+			variable := observedVariable{streamed.slaveIndex, streamed.variable.Type, streamed.variable.ValueReference}
+			if sim.trendObservations[variable] == 0 && !streamObserves(sim, variable, stream) {
+				observerStopObserving(sim.TrendObserver, streamed.slaveIndex, streamed.variable.Type, streamed.variable.ValueReference)
+			}
+		}
+		sim.stepStreams = append(sim.stepStreams[:i], sim.stepStreams[i+1:]...)
+		close(stream.Samples)
+		return
+	}
+}
+
+// closeStepStreams closes the channels of all step streams when the simulation is torn down.
+func closeStepStreams(sim *Simulation) {
+	for _, stream := range sim.stepStreams {
+		close(stream.Samples)
+	}
+	sim.stepStreams = nil
+}
+
+func sendStepSamples(sim *Simulation, status *structs.SimulationStatus) {
+	if !status.Loaded {
+		return
+	}
+	for _, stream := range sim.stepStreams {
+		for _, sample := range stepSamples(sim, status, stream) {
+			select {
+			case stream.Samples <- sample:
+			default:
+				stream.dropped++
+			}
+		}
+		if stream.dropped > 0 {
+			log.Println("Step stream dropped", stream.dropped, "steps, since its receiver doesn't keep up")
+			stream.dropped = 0
+		}
+	}
+}
+
+// stepSamples returns the samples of the steps of a stream taken since the previous call.
+func stepSamples(sim *Simulation, status *structs.SimulationStatus, stream *StepStream) []StepSample {
+	others := map[string]interface{}{}
+	for _, module := range variableValues(sim, status, stream.others) {
+		for _, signal := range module.Signals {
+			others[strCat(module.Name, ".", signal.Name)] = signal.Value
+		}
+	}
+
+	if len(stream.variables) == 0 {
+		now := getExecutionStatus(sim.Execution).time
+		if now == stream.lastTime {
+			return nil
+		}
+		stream.lastTime = now
+		return []StepSample{{Time: now, Values: others}}
+	}
+
+	times := map[int64]float64{}
+	values := map[int64]map[string]interface{}{}
+	for _, streamed := range stream.variables {
+		count := observerLatestStep(sim.TrendObserver, streamed.slaveIndex) - stream.nextStep + 1
+		if count > stepStreamMaxSteps {
+			count = stepStreamMaxSteps
+		}
+		steps, stepTimes, stepValues := observerSamplesFrom(sim.TrendObserver, streamed.slaveIndex, streamed.variable.Type, streamed.variable.ValueReference, stream.nextStep, int(count))
+		for i, step := range steps {
+			if _, exists := values[step]; !exists {
+				values[step] = map[string]interface{}{}
+				times[step] = stepTimes[i]
+			}
+			if streamed.variable.Type == "Integer" {
+				values[step][streamed.name] = int(stepValues[i])
+			} else {
+				values[step][streamed.name] = stepValues[i]
+			}
+		}
+	}
+
+	var steps []int64
+	for step := range values {
+		steps = append(steps, step)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i] < steps[j] })
+	samples := make([]StepSample, 0, len(steps))
+	for _, step := range steps {
+		// Simulators stepping less often than others keep their last values.
+		for name, value := range values[step] {
+			stream.last[name] = value
+		}
+		sample := StepSample{Time: times[step], Values: map[string]interface{}{}}
+		for name, value := range stream.last {
+			sample.Values[name] = value
+		}
+		for name, value := range others {
+			sample.Values[name] = value
+		}
+		samples = append(samples, sample)
+		stream.nextStep = step + 1
+	}
+	return samples
+}
@@ -7,26 +7,74 @@ package libcosim
 import (
 	"cosim-demo-app/structs"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"log"
 	"math/rand"
 	"os"
-	"path/filepath"
 	"strconv"
 )
 
-func generateNextTrendId(status *structs.SimulationStatus) int {
-	var maxId = 0
-	for _, trend := range status.Trends {
-		if trend.Id > maxId {
-			maxId = trend.Id
+type observedVariable struct {
+	slaveIndex     int
+	valueType      string
+	valueReference int
+}
+
+// trendObserverStart starts observing a variable in the trend observer, unless some other
+// trend signal or a step stream already observes it. Every call must be matched by a call to trendObserverStop.
+func trendObserverStart(sim *Simulation, slaveIndex int, valueType string, valueReference int) error {
+	variable := observedVariable{slaveIndex, valueType, valueReference}
+	if sim.trendObservations == nil {
+		sim.trendObservations = map[observedVariable]int{}
+	}
+	if sim.trendObservations[variable] == 0 && !streamObserves(sim, variable, nil) {
+		err := observerStartObserving(sim.TrendObserver, slaveIndex, valueType, valueReference)
+		if err != nil {
+			return err
 		}
 	}
+	sim.trendObservations[variable]++
+	return nil
+}
+
+// trendObserverStop stops observing a variable in the trend observer when no trend signal uses it anymore.
+func trendObserverStop(sim *Simulation, slaveIndex int, valueType string, valueReference int) error {
+	variable := observedVariable{slaveIndex, valueType, valueReference}
+	if sim.trendObservations[variable] > 1 {
+		sim.trendObservations[variable]--
+		return nil
+	}
+	delete(sim.trendObservations, variable)
+	if streamObserves(sim, variable, nil) {
+		return nil
+	}
+	return observerStopObserving(sim.TrendObserver, slaveIndex, valueType, valueReference)
+}
 
-	return maxId + 1
+// generateNextTrendId never hands out an id twice, not even after the trends are cleared, so that
+// clients can't mistake a new trend for a removed one.
+func generateNextTrendId(status *structs.SimulationStatus) int {
+	status.NextTrendId++
+	return status.NextTrendId
+}
+
+// findTrend returns the position in status.Trends of the trend with the given id.
+func findTrend(status *structs.SimulationStatus, trendId string) (int, error) {
+	id, err := strconv.Atoi(trendId)
+	if err != nil {
+		return -1, errors.New(strCat("Cannot parse trend id as integer: ", trendId))
+	}
+	for idx, trend := range status.Trends {
+		if trend.Id == id {
+			return idx, nil
+		}
+	}
+	return -1, errors.New(strCat("Trend with id ", trendId, " does not exist"))
 }
 
-func addNewTrend(status *structs.SimulationStatus, plotType string, label string) (bool, string) {
+// addNewTrend adds a trend and returns its id.
+func addNewTrend(status *structs.SimulationStatus, plotType string, label string) (bool, string, int) {
 	id := generateNextTrendId(status)
 
 	status.Trends = append(status.Trends, structs.Trend{
@@ -37,64 +85,218 @@ func addNewTrend(status *structs.SimulationStatus, plotType string, label string
 		Spec: structs.TrendSpec{
 			Auto:  true,
 			Range: 10.0}})
-	return true, "Added new trend"
+	return true, "Added new trend", id
+}
+
+func validateAxis(axis string) error {
+	switch axis {
+	case "", "x", "y":
+		return nil
+	}
+	return errors.New(strCat("Unknown axis: ", axis, ", expected x or y"))
 }
 
-func addToTrend(sim *Simulation, status *structs.SimulationStatus, module string, signal string, plotIndex string) (bool, string) {
+func addToTrend(sim *Simulation, status *structs.SimulationStatus, module string, signal string, trendId string, axis string) (bool, string) {
 
-	idx, err := strconv.Atoi(plotIndex)
-	if err != nil {
-		message := strCat("Cannot parse plotIndex as integer", plotIndex, ", ", err.Error())
+	if err := validateAxis(axis); err != nil {
+		message := err.Error()
 		log.Println(message)
 		return false, message
 	}
 
-	fmu, err := findFmu(sim.MetaData, module)
+	idx, err := findTrend(status, trendId)
 	if err != nil {
 		message := err.Error()
 		log.Println(message)
 		return false, message
 	}
 
-	variable, err := findVariable(fmu, signal)
+	trendSignal, err := createTrendSignal(sim, status, module, signal)
 	if err != nil {
 		message := err.Error()
 		log.Println(message)
 		return false, message
 	}
+	trendSignal.Axis = axis
 
-	err = observerStartObserving(sim.TrendObserver, fmu.ExecutionIndex, variable.Type, variable.ValueReference)
+	err = startObservingTrendSignal(sim, status, trendSignal)
 	if err != nil {
 		message := strCat("Cannot start observing variable ", lastErrorMessage())
 		log.Println(message)
 		return false, message
 	}
 
-	status.Trends[idx].TrendSignals = append(status.Trends[idx].TrendSignals, structs.TrendSignal{
+	status.Trends[idx].TrendSignals = append(status.Trends[idx].TrendSignals, trendSignal)
+
+	return true, "Added variable to trend"
+}
+
+func createTrendSignal(sim *Simulation, status *structs.SimulationStatus, module string, signal string) (structs.TrendSignal, error) {
+	if derived, err := findDerivedSignal(status, module, signal); err == nil {
+		return derivedTrendSignal(derived), nil
+	}
+	if sim.run != nil {
+		if trendSignal, err := recordedTrendSignal(sim, module, signal); err == nil {
+			return trendSignal, nil
+		}
+	}
+
+	fmu, err := findFmu(sim.MetaData, module)
+	if err != nil {
+		return structs.TrendSignal{}, err
+	}
+
+	variable, err := findVariable(fmu, signal)
+	if err != nil {
+		return structs.TrendSignal{}, err
+	}
+
+	return structs.TrendSignal{
 		Module:         module,
 		SlaveIndex:     fmu.ExecutionIndex,
 		Signal:         signal,
 		Causality:      variable.Causality,
 		Type:           variable.Type,
-		ValueReference: variable.ValueReference})
+		ValueReference: variable.ValueReference}, nil
+}
 
-	return true, "Added variable to trend"
+func startObservingTrendSignal(sim *Simulation, status *structs.SimulationStatus, trendSignal structs.TrendSignal) error {
+	if trendSignal.Causality == recordedCausality {
+		return nil
+	}
+	if trendSignal.Causality != derivedCausality {
+		return trendObserverStart(sim, trendSignal.SlaveIndex, trendSignal.Type, trendSignal.ValueReference)
+	}
+	references, err := derivedReferences(sim, status, trendSignal)
+	if err != nil {
+		return err
+	}
+	for _, reference := range references {
+		err = trendObserverStart(sim, reference.slaveIndex, "Real", reference.valueReference)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stopObservingTrendSignal(sim *Simulation, status *structs.SimulationStatus, trendSignal structs.TrendSignal) error {
+	if trendSignal.Causality == recordedCausality {
+		return nil
+	}
+	if trendSignal.Causality != derivedCausality {
+		return trendObserverStop(sim, trendSignal.SlaveIndex, trendSignal.Type, trendSignal.ValueReference)
+	}
+	references, err := derivedReferences(sim, status, trendSignal)
+	if err != nil {
+		return err
+	}
+	for _, reference := range references {
+		err = trendObserverStop(sim, reference.slaveIndex, "Real", reference.valueReference)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setTrendSignalAxis(status *structs.SimulationStatus, trendId string, module string, signal string, axis string) (bool, string) {
+	if err := validateAxis(axis); err != nil {
+		return false, err.Error()
+	}
+
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	for i, trendSignal := range status.Trends[idx].TrendSignals {
+		if trendSignal.Module == module && trendSignal.Signal == signal {
+			status.Trends[idx].TrendSignals[i].Axis = axis
+			return true, strCat("Moved ", module, ".", signal, " to axis ", axis)
+		}
+	}
+	return false, strCat("Variable ", module, ".", signal, " is not in trend ", trendId)
 }
 
-func setTrendLabel(status *structs.SimulationStatus, trendIndex string, trendLabel string) (bool, string) {
-	idx, _ := strconv.Atoi(trendIndex)
+// setTrendSignalSettings sets the display settings of a trend signal from the arguments
+// y-axis, scale, offset, color and unit. Trailing arguments may be left out.
+func setTrendSignalSettings(status *structs.SimulationStatus, trendId string, module string, signal string, settings []string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	var variable structs.PlotVariable
+	if len(settings) > 0 && len(settings[0]) > 0 {
+		if variable.YAxis, err = strconv.Atoi(settings[0]); err != nil {
+			return false, strCat("Cannot parse y-axis as integer: ", settings[0])
+		}
+	}
+	if len(settings) > 1 && len(settings[1]) > 0 {
+		scale, err := strconv.ParseFloat(settings[1], 64)
+		if err != nil {
+			return false, strCat("Cannot parse scale as double: ", settings[1])
+		}
+		variable.Scale = &scale
+	}
+	if len(settings) > 2 && len(settings[2]) > 0 {
+		if variable.Offset, err = strconv.ParseFloat(settings[2], 64); err != nil {
+			return false, strCat("Cannot parse offset as double: ", settings[2])
+		}
+	}
+	if len(settings) > 3 {
+		variable.Color = settings[3]
+	}
+	if len(settings) > 4 {
+		variable.Unit = settings[4]
+	}
+
+	for i, trendSignal := range status.Trends[idx].TrendSignals {
+		if trendSignal.Module == module && trendSignal.Signal == signal {
+			applySignalSettings(&status.Trends[idx].TrendSignals[i], variable)
+			return true, strCat("Changed settings for ", module, ".", signal)
+		}
+	}
+	return false, strCat("Variable ", module, ".", signal, " is not in trend ", trendId)
+}
+
+// scaleValues returns a scaled copy of the values. Without a scale the values are only offset.
+func scaleValues(values []float64, scale *float64, offset float64) []float64 {
+	factor := 1.0
+	if scale != nil {
+		factor = *scale
+	}
+	if factor == 1 && offset == 0 {
+		return values
+	}
+	scaled := make([]float64, len(values))
+	for i, value := range values {
+		scaled[i] = value*factor + offset
+	}
+	return scaled
+}
+
+func setTrendLabel(status *structs.SimulationStatus, trendId string, trendLabel string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
 	var uuid = rand.Intn(9999)*rand.Intn(9999) + rand.Intn(9999)
 	status.Trends[idx].Label = strCat(trendLabel, " #", strconv.Itoa(uuid))
 	return true, "Modified trend label"
 }
 
-func removeAllFromTrend(sim *Simulation, status *structs.SimulationStatus, trendIndex string) (bool, string) {
+func removeAllFromTrend(sim *Simulation, status *structs.SimulationStatus, trendId string) (bool, string) {
 	var success = true
 	var message = "Removed all variables from trend"
 
-	idx, _ := strconv.Atoi(trendIndex)
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
 	for _, trendSignal := range status.Trends[idx].TrendSignals {
-		err := observerStopObserving(sim.TrendObserver, trendSignal.SlaveIndex, trendSignal.Type, trendSignal.ValueReference)
+		err := stopObservingTrendSignal(sim, status, trendSignal)
 		if err != nil {
 			message = strCat("Cannot stop observing variable: ", lastErrorMessage())
 			success = false
@@ -102,12 +304,101 @@ func removeAllFromTrend(sim *Simulation, status *structs.SimulationStatus, trend
 		}
 	}
 	status.Trends[idx].TrendSignals = []structs.TrendSignal{}
+	if dropOrphanedTrigger(sim, &status.Trends[idx]) {
+		message = strCat(message, " and its trigger")
+	}
 	return success, message
 }
 
-func removeTrend(status *structs.SimulationStatus, trendIndex string) (bool, string) {
-	idx, _ := strconv.Atoi(trendIndex)
+func removeFromTrend(sim *Simulation, status *structs.SimulationStatus, trendId string, module string, signal string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	trendSignals := status.Trends[idx].TrendSignals
+	for i, trendSignal := range trendSignals {
+		if trendSignal.Module == module && trendSignal.Signal == signal {
+			err = stopObservingTrendSignal(sim, status, trendSignal)
+			if err != nil {
+				log.Println("Cannot stop observing", err)
+				return false, strCat("Cannot stop observing variable: ", err.Error())
+			}
+			status.Trends[idx].TrendSignals = append(trendSignals[:i], trendSignals[i+1:]...)
+			if dropOrphanedTrigger(sim, &status.Trends[idx]) {
+				return true, strCat("Removed ", module, ".", signal, " and the trigger on it from trend")
+			}
+			return true, strCat("Removed ", module, ".", signal, " from trend")
+		}
+	}
+	return false, strCat("Variable ", module, ".", signal, " is not in trend ", trendId)
+}
+
+func reorderTrendSignal(status *structs.SimulationStatus, trendId string, fromPosition string, toPosition string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+	trendSignals := status.Trends[idx].TrendSignals
+
+	from, err := strconv.Atoi(fromPosition)
+	if err != nil || from < 0 || from >= len(trendSignals) {
+		return false, strCat("Invalid signal position: ", fromPosition)
+	}
+	to, err := strconv.Atoi(toPosition)
+	if err != nil || to < 0 || to >= len(trendSignals) {
+		return false, strCat("Invalid signal position: ", toPosition)
+	}
+
+	trendSignal := trendSignals[from]
+	trendSignals = append(trendSignals[:from], trendSignals[from+1:]...)
+	trendSignals = append(trendSignals[:to], append([]structs.TrendSignal{trendSignal}, trendSignals[to:]...)...)
+	status.Trends[idx].TrendSignals = trendSignals
+	return true, strCat("Moved ", trendSignal.Module, ".", trendSignal.Signal, " to position ", toPosition)
+}
+
+// moveTrendSignal moves a signal from one trend to another. The variable stays observed,
+// so no observer bookkeeping is needed.
+func moveTrendSignal(sim *Simulation, status *structs.SimulationStatus, fromTrendId string, module string, signal string, toTrendId string) (bool, string) {
+	fromIdx, err := findTrend(status, fromTrendId)
+	if err != nil {
+		return false, err.Error()
+	}
+	toIdx, err := findTrend(status, toTrendId)
+	if err != nil {
+		return false, err.Error()
+	}
 
+	trendSignals := status.Trends[fromIdx].TrendSignals
+	for i, trendSignal := range trendSignals {
+		if trendSignal.Module == module && trendSignal.Signal == signal {
+			status.Trends[fromIdx].TrendSignals = append(trendSignals[:i], trendSignals[i+1:]...)
+			trendSignal.TrendXValues = nil
+			trendSignal.TrendYValues = nil
+			status.Trends[toIdx].TrendSignals = append(status.Trends[toIdx].TrendSignals, trendSignal)
+			if dropOrphanedTrigger(sim, &status.Trends[fromIdx]) {
+				return true, strCat("Moved ", module, ".", signal, " to trend ", status.Trends[toIdx].Label, " and removed the trigger on it")
+			}
+			return true, strCat("Moved ", module, ".", signal, " to trend ", status.Trends[toIdx].Label)
+		}
+	}
+	return false, strCat("Variable ", module, ".", signal, " is not in trend ", fromTrendId)
+}
+
+func removeTrend(sim *Simulation, status *structs.SimulationStatus, trendId string) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	for _, trendSignal := range status.Trends[idx].TrendSignals {
+		err := stopObservingTrendSignal(sim, status, trendSignal)
+		if err != nil {
+			log.Println("Cannot stop observing", err)
+		}
+	}
+
+	delete(sim.triggerScans, status.Trends[idx].Id)
 	if len(status.Trends) > 1 {
 		status.Trends = append(status.Trends[:idx], status.Trends[idx+1:]...)
 	} else {
@@ -117,25 +408,40 @@ func removeTrend(status *structs.SimulationStatus, trendIndex string) (bool, str
 	return true, "Removed trend"
 }
 
-func activeTrend(status *structs.SimulationStatus, trendIndex string) (bool, string) {
-	if len(trendIndex) > 0 {
-		idx, err := strconv.Atoi(trendIndex)
+func activeTrend(status *structs.SimulationStatus, view *structs.ClientView, trendId string) (bool, string) {
+	if len(trendId) > 0 {
+		idx, err := findTrend(status, trendId)
 		if err != nil {
-			return false, strCat("Could not parse trend index: ", trendIndex, " ", err.Error())
+			return false, err.Error()
 		}
-		status.ActiveTrend = idx
+		view.ActiveTrend = status.Trends[idx].Id
 	} else {
-		status.ActiveTrend = -1
+		view.ActiveTrend = -1
 	}
-	return true, "Changed active trend index"
+	return true, "Changed active trend"
 }
 
-func generatePlotData(sim *Simulation, status *structs.SimulationStatus) {
-	for idx, trend := range status.Trends {
-		if status.ActiveTrend != idx {
+func setTrendSpec(status *structs.SimulationStatus, trendId string, spec structs.TrendSpec) (bool, string) {
+	idx, err := findTrend(status, trendId)
+	if err != nil {
+		return false, err.Error()
+	}
+	status.Trends[idx].Spec = spec
+	return true, "Changed trend range"
+}
+
+// generatePlotData returns a copy of the trends with the plot data of the active trend filled in.
+func generatePlotData(sim *Simulation, status *structs.SimulationStatus, activeTrend int) []structs.Trend {
+	trends := make([]structs.Trend, len(status.Trends))
+	for t := range status.Trends {
+		trend := status.Trends[t]
+		trend.TrendSignals = append([]structs.TrendSignal{}, trend.TrendSignals...)
+		trends[t] = trend
+		if activeTrend != trend.Id {
 			for i, _ := range trend.TrendSignals {
 				trend.TrendSignals[i].TrendXValues = nil
 				trend.TrendSignals[i].TrendYValues = nil
+				clearReferenceOverlay(&trend.TrendSignals[i])
 			}
 			continue
 		}
@@ -144,27 +450,78 @@ func generatePlotData(sim *Simulation, status *structs.SimulationStatus) {
 			if len(trend.TrendSignals) > 0 {
 				for i, _ := range trend.TrendSignals {
 					var signal = &trend.TrendSignals[i]
-					switch signal.Type {
-					case "Real":
-						observerGetRealSamples(sim.TrendObserver, signal, trend.Spec)
+					signal.TrendXValues = nil
+					signal.TrendYValues = nil
+					switch {
+					case signal.Causality == derivedCausality:
+						derivedGetRealSamples(sim, status, signal, trend.Spec)
+					case signal.Causality == recordedCausality:
+						signal.TrendXValues, signal.TrendYValues = recordedRealSamples(sim, signal, trend.Spec)
+					case signal.Type == "Real" && status.Loaded:
+						signal.TrendXValues, signal.TrendYValues = bufferedRealSamples(sim, signal.SlaveIndex, signal.ValueReference, trend.Spec)
 					}
+					signal.TrendYValues = scaleValues(signal.TrendYValues, signal.Scale, signal.Offset)
+					referenceOverlay(sim, signal)
 				}
 			}
 			break
 		case "scatter":
-			signalCount := len(trend.TrendSignals)
-			if signalCount > 0 {
-				for j := 0; (j + 1) < signalCount; j += 2 {
-					var signal1 = &trend.TrendSignals[j]
-					var signal2 = &trend.TrendSignals[j+1]
-					if signal1.Type == "Real" && signal2.Type == "Real" {
-						observerGetRealSynchronizedSamples(sim.TrendObserver, signal1, signal2, trend.Spec)
-					}
+			for _, pair := range xyPairs(trend.TrendSignals) {
+				var xSignal = &trend.TrendSignals[pair[0]]
+				var ySignal = &trend.TrendSignals[pair[1]]
+				if xSignal.Type != "Real" || ySignal.Type != "Real" {
+					continue
+				}
+				ySignal.TrendXValues = nil
+				ySignal.TrendYValues = nil
+				// Fixed ranges may reach back past the trend observer buffer, into the history.
+				fromHistory := status.Loaded && sim.history != nil && !trend.Spec.Auto
+				if xSignal.Causality == derivedCausality || ySignal.Causality == derivedCausality ||
+					xSignal.Causality == recordedCausality || ySignal.Causality == recordedCausality || fromHistory {
+					derivedGetRealSynchronizedSamples(sim, status, xSignal, ySignal, trend.Spec)
+				} else if status.Loaded {
+					observerGetRealSynchronizedSamples(sim.TrendObserver, xSignal, ySignal, trend.Spec)
+				}
+				if ySignal.TrendYValues != nil {
+					ySignal.TrendXValues = scaleValues(ySignal.TrendXValues, xSignal.Scale, xSignal.Offset)
+					ySignal.TrendYValues = scaleValues(ySignal.TrendYValues, ySignal.Scale, ySignal.Offset)
+					xSignal.TrendXValues = ySignal.TrendXValues
 				}
 			}
 			break
 		}
 	}
+	return trends
+}
+
+// xyPairs returns the (x, y) signal index pairs of a scatter plot. When no signal
+// has an explicit axis, signals are paired as (j, j+1). Otherwise every y signal
+// is paired with the closest preceding x signal, or the first x signal if none precedes it.
+func xyPairs(signals []structs.TrendSignal) (pairs [][2]int) {
+	firstX := -1
+	for i, signal := range signals {
+		if signal.Axis == "x" {
+			firstX = i
+			break
+		}
+	}
+
+	if firstX < 0 {
+		for j := 0; (j + 1) < len(signals); j += 2 {
+			pairs = append(pairs, [2]int{j, j + 1})
+		}
+		return pairs
+	}
+
+	currentX := firstX
+	for i, signal := range signals {
+		if signal.Axis == "x" {
+			currentX = i
+			continue
+		}
+		pairs = append(pairs, [2]int{currentX, i})
+	}
+	return pairs
 }
 
 func parsePlotConfig(pathToFile string) (data structs.PlotConfig, err error) {
@@ -190,31 +547,21 @@ func parsePlotConfig(pathToFile string) (data structs.PlotConfig, err error) {
 	return data, nil
 }
 
-func setupPlotsFromConfig(sim *Simulation, status *structs.SimulationStatus, configDir string) {
-	if hasFile(configDir, "PlotConfig.json") {
-		log.Println("We have a PlotConfig")
-		pathToFile := filepath.Join(configDir, "PlotConfig.json")
-		plotConfig, err := parsePlotConfig(pathToFile)
-
-		if err != nil {
-			log.Println("Can't parse PlotConfig.json:", err.Error())
-			return
-		}
-
-		for idx, plot := range plotConfig.Plots {
-			success, message := addNewTrend(status, plot.PlotType, plot.Label)
-			if !success {
-				log.Println("Could not add new plot:", message)
-			} else {
-				plotIdx := strconv.Itoa(idx)
-				for _, variable := range plot.PlotVariables {
-					success, message := addToTrend(sim, status, variable.Simulator, variable.Variable, plotIdx)
-					if !success {
-						log.Println("Could not add variable to plot:", message)
-					}
-				}
-			}
-		}
+func setupPlotsFromConfig(sim *Simulation, status *structs.SimulationStatus, configDir string) *structs.PlotConfigReport {
+	pathToFile := defaultPlotConfigPath(configDir)
+	if !doesFileExist(pathToFile) {
+		return nil
+	}
+	log.Println("Setting up plots from", pathToFile)
+	report := &structs.PlotConfigReport{File: pathToFile}
+	plotConfig, err := parsePlotConfig(pathToFile)
 
+	if err != nil {
+		log.Println("Can't parse", pathToFile, ":", err.Error())
+		report.Errors = append(report.Errors, strCat("Can't parse plot configuration: ", err.Error()))
+		return report
 	}
+
+	applyPlotConfig(sim, status, plotConfig, report)
+	return report
 }
@@ -6,6 +6,7 @@ package libcosim
 
 import (
 	"log"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -26,3 +27,17 @@ func strCat(strs ...string) string {
 	}
 	return sb.String()
 }
+
+// WithinFolder tells whether path is folder or inside it.
+func WithinFolder(folder string, path string) bool {
+	absFolder, err := filepath.Abs(folder)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	relative, err := filepath.Rel(absFolder, absPath)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,159 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package libcosim
+
+import (
+	"cosim-demo-app/structs"
+	"log"
+	"strconv"
+)
+
+// The watch list holds variables of any simulators, or derived signals, shown together in one table.
+// The signal subscriptions on the other hand are limited to the variables of one module.
+// Like those, each client has a watch list of its own.
+
+func findWatchedVariable(view *structs.ClientView, module string, variable string) int {
+	for idx, watched := range view.WatchList {
+		if watched.Module == module && watched.Variable.Name == variable {
+			return idx
+		}
+	}
+	return -1
+}
+
+func resolveWatchedVariable(sim *Simulation, status *structs.SimulationStatus, module string, variable string) (structs.WatchedVariable, error) {
+	if derived, err := findDerivedSignal(status, module, variable); err == nil {
+		return structs.WatchedVariable{
+			Module: module,
+			Variable: structs.Variable{
+				Name:           derived.Name,
+				ValueReference: -1,
+				Causality:      derivedCausality,
+				Type:           "Real",
+			},
+		}, nil
+	}
+	fmu, err := findFmu(sim.MetaData, module)
+	if err != nil {
+		return structs.WatchedVariable{}, err
+	}
+	found, err := findVariable(fmu, variable)
+	if err != nil {
+		return structs.WatchedVariable{}, err
+	}
+	return structs.WatchedVariable{Module: module, Variable: found}, nil
+}
+
+// watchVariables adds the variables given as module and variable name pairs to the watch list.
+func watchVariables(sim *Simulation, status *structs.SimulationStatus, view *structs.ClientView, pairs []string) (bool, string) {
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return false, "Expected pairs of module and variable names"
+	}
+	added := 0
+	for j := 0; j < len(pairs); j += 2 {
+		if findWatchedVariable(view, pairs[j], pairs[j+1]) >= 0 {
+			continue
+		}
+		watched, err := resolveWatchedVariable(sim, status, pairs[j], pairs[j+1])
+		if err != nil {
+			log.Println(err.Error())
+			return false, strCat("Could not watch ", pairs[j], ".", pairs[j+1], ": ", err.Error())
+		}
+		view.WatchList = append(view.WatchList, watched)
+		added++
+	}
+	return true, strCat("Added ", strconv.Itoa(added), " variable(s) to the watch list")
+}
+
+func unwatchVariable(view *structs.ClientView, module string, variable string) (bool, string) {
+	idx := findWatchedVariable(view, module, variable)
+	if idx < 0 {
+		return false, strCat("Variable ", module, ".", variable, " is not in the watch list")
+	}
+	// Copied, since earlier copies of the view may still share the list.
+	remaining := make([]structs.WatchedVariable, 0, len(view.WatchList)-1)
+	remaining = append(remaining, view.WatchList[:idx]...)
+	view.WatchList = append(remaining, view.WatchList[idx+1:]...)
+	return true, strCat("Removed ", module, ".", variable, " from the watch list")
+}
+
+func clearWatchList(view *structs.ClientView) (bool, string) {
+	view.WatchList = nil
+	return true, "Cleared the watch list"
+}
+
+func watchListData(sim *Simulation, status *structs.SimulationStatus, view structs.ClientView) []structs.Module {
+	return variableValues(sim, status, view.WatchList)
+}
+
+// VariableValues reads the values of the variables given as module and variable name pairs, outside the command loop.
+func VariableValues(sim *Simulation, status *structs.SimulationStatus, pairs []string) ([]structs.Module, error) {
+	sim.lock.RLock()
+	defer sim.lock.RUnlock()
+	if !status.Loaded {
+		return nil, ErrNotLoaded
+	}
+	var variables []structs.WatchedVariable
+	for j := 0; j+1 < len(pairs); j += 2 {
+		watched, err := resolveWatchedVariable(sim, status, pairs[j], pairs[j+1])
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, watched)
+	}
+	return variableValues(sim, status, variables), nil
+}
+
+// variableValues reads the values of variables, one module per simulator in the order they were
+// first given. The variables of a simulator are read per type with one observer call each.
+func variableValues(sim *Simulation, status *structs.SimulationStatus, watchedVariables []structs.WatchedVariable) (modules []structs.Module) {
+	var order []string
+	variables := map[string][]structs.Variable{}
+	for _, watched := range watchedVariables {
+		if _, exists := variables[watched.Module]; !exists {
+			order = append(order, watched.Module)
+		}
+		variables[watched.Module] = append(variables[watched.Module], watched.Variable)
+	}
+
+	for _, name := range order {
+		module := structs.Module{Name: name}
+		if isDerivedModule(status, name) {
+			for _, variable := range variables[name] {
+				derived, err := findDerivedSignal(status, name, variable.Name)
+				if err != nil {
+					continue
+				}
+				value, err := derivedValue(sim, derived)
+				if err == errNotFinite {
+					continue
+				} else if err != nil {
+					log.Println("Could not evaluate derived signal:", err.Error())
+					continue
+				}
+				module.Signals = append(module.Signals, structs.Signal{
+					Name:      variable.Name,
+					Causality: derivedCausality,
+					Type:      "Real",
+					Value:     value,
+				})
+			}
+			modules = append(modules, module)
+			continue
+		}
+
+		slave, err := findFmu(sim.MetaData, name)
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		module.Signals = append(module.Signals, observerGetReals(sim.Observer, variables[name], slave.ExecutionIndex)...)
+		module.Signals = append(module.Signals, observerGetIntegers(sim.Observer, variables[name], slave.ExecutionIndex)...)
+		module.Signals = append(module.Signals, observerGetBooleans(sim.Observer, variables[name], slave.ExecutionIndex)...)
+		module.Signals = append(module.Signals, observerGetStrings(sim.Observer, variables[name], slave.ExecutionIndex)...)
+		modules = append(modules, module)
+	}
+	return modules
+}
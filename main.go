@@ -8,28 +8,41 @@ import (
 	"cosim-demo-app/libcosim"
 	"cosim-demo-app/server"
 	"cosim-demo-app/structs"
+	"flag"
 )
 
 func main() {
+	grpcAddress := flag.String("grpc", "", "address for the gRPC service to listen on, like :8001, disabled if not given")
+	webhooksFile := flag.String("webhooks", "", "JSON file with the webhooks and the URL prefixes allowed for webhooks added through the API")
+	flag.Parse()
+
 	libcosim.SetupLogging()
 	sim := libcosim.CreateEmptySimulation()
 
 	// Creating a command channel
-	cmd := make(chan []string, 10)
-	state := make(chan structs.JsonResponse, 10)
+	cmd := make(chan structs.ClientCommand, 10)
+	clients := libcosim.NewClients()
+	metrics := libcosim.NewMetrics()
 
 	simulationStatus := structs.SimulationStatus{
 		Loaded:     false,
 		Status:     "stopped",
 		Trends:     []structs.Trend{},
 		LibVersion: libcosim.Version(),
+		View:       libcosim.NewClientView(),
 	}
 
 	// Passing the channel to the go routine
-	go libcosim.StateUpdateLoop(state, &simulationStatus, &sim)
-	go libcosim.CommandLoop(state, &sim, cmd, &simulationStatus)
+	go libcosim.StateUpdateLoop(clients, &simulationStatus, &sim, metrics)
+	go libcosim.CommandLoop(clients, &sim, cmd, &simulationStatus, metrics)
+
+	go server.GrpcServer(*grpcAddress, cmd, clients, &simulationStatus, &sim)
+	go server.MqttBridge(cmd, &simulationStatus, &sim)
+	go server.ModbusServer(cmd, &simulationStatus, &sim)
+	go server.OpcuaServer(cmd, clients, &simulationStatus, &sim)
+	go server.UdpStream(cmd, &simulationStatus, &sim)
 
 	//Passing the channel to the server
-	server.Server(cmd, state, &simulationStatus, &sim)
+	server.Server(cmd, clients, &simulationStatus, &sim, metrics, *webhooksFile)
 	close(cmd)
 }
@@ -0,0 +1,304 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/structs"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// In delta mode a client first gets a full snapshot of the state:
+//
+//	{"update": "snapshot", ...all fields of the state}
+//
+// and afterwards only what changed since the previous message:
+//
+//	{"update": "delta", "changed": {field: value}, "removed": [field], "trend-data": [...]}
+//
+// The trends in "changed" never hold samples. The samples come in "trend-data", one entry per signal
+// of the active trend: {"id", "module", "signal", "append", "xvals", "yvals"}. When append is true,
+// xvals and yvals only hold the time series samples after the last ones sent, and the client drops
+// the samples that fall out of the trend range itself. Otherwise they replace the previous samples,
+// as they do after the range, scale, offset or reference overlay of a signal changed.
+// Reference and difference samples ("ref-xvals", "ref-yvals", "diff-yvals") are only sent when they
+// change, and replace the previous ones. Samples and values that aren't finite are sent as null.
+
+type signalKey struct {
+	trendId int
+	module  string
+	signal  string
+}
+
+// signalSettings are the settings of a signal that change its samples already sent.
+type signalSettings struct {
+	spec      structs.TrendSpec
+	scale     float64
+	offset    float64
+	reference bool
+}
+
+type sentSignal struct {
+	lastTime float64
+	settings signalSettings
+	arrays   map[string][]float64
+}
+
+type deltaEncoder struct {
+	last    map[string]interface{}
+	signals map[signalKey]sentSignal
+}
+
+func newDeltaEncoder() *deltaEncoder {
+	return &deltaEncoder{}
+}
+
+// toMap converts a response to the generic form of its JSON encoding.
+func toMap(response structs.JsonResponse) map[string]interface{} {
+	return jsonValue(reflect.ValueOf(response)).(map[string]interface{})
+}
+
+// jsonValue converts a value to the generic form of its JSON encoding, following the json tags of
+// its fields. Unlike json.Marshal it doesn't fail on NaN or infinite floats, but turns them into nil.
+func jsonValue(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return jsonValue(value.Elem())
+	case reflect.Float32, reflect.Float64:
+		f := value.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+		return f
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Bool:
+		return value.Bool()
+	case reflect.String:
+		return value.String()
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = jsonValue(value.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		fields := make(map[string]interface{}, value.Len())
+		for _, key := range value.MapKeys() {
+			fields[fmt.Sprint(key.Interface())] = jsonValue(value.MapIndex(key))
+		}
+		return fields
+	case reflect.Struct:
+		fields := map[string]interface{}{}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name, options := field.Name, ""
+			if tag, tagged := field.Tag.Lookup("json"); tagged {
+				if tag == "-" {
+					continue
+				}
+				if comma := strings.Index(tag, ","); comma >= 0 {
+					tag, options = tag[:comma], tag[comma:]
+				}
+				if tag != "" {
+					name = tag
+				}
+			}
+			if strings.Contains(options+",", ",omitempty,") && isEmptyValue(value.Field(i)) {
+				continue
+			}
+			fields[name] = jsonValue(value.Field(i))
+		}
+		return fields
+	}
+	return nil
+}
+
+// isEmptyValue tells if omitempty omits a value, the way encoding/json does.
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Struct:
+		return false
+	}
+	return value.IsZero()
+}
+
+// finiteSamples returns the samples with the values that aren't finite sent as nil.
+func finiteSamples(values []float64) interface{} {
+	for _, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return jsonValue(reflect.ValueOf(values))
+		}
+	}
+	return values
+}
+
+func currentSettings(trend structs.Trend, signal structs.TrendSignal) signalSettings {
+	settings := signalSettings{spec: trend.Spec, scale: 1, offset: signal.Offset, reference: signal.Reference}
+	if signal.Scale != nil {
+		settings.scale = *signal.Scale
+	}
+	return settings
+}
+
+// withoutSamples returns a copy of the trends without samples.
+func withoutSamples(trends []structs.Trend) []structs.Trend {
+	stripped := make([]structs.Trend, len(trends))
+	for t, trend := range trends {
+		stripped[t] = trend
+		stripped[t].TrendSignals = make([]structs.TrendSignal, len(trend.TrendSignals))
+		for i, signal := range trend.TrendSignals {
+			signal.TrendXValues = nil
+			signal.TrendYValues = nil
+			signal.ReferenceXValues = nil
+			signal.ReferenceYValues = nil
+			signal.DiffYValues = nil
+			stripped[t].TrendSignals[i] = signal
+		}
+	}
+	return stripped
+}
+
+func replacedArrays(signal structs.TrendSignal) map[string][]float64 {
+	return map[string][]float64{
+		"xvals":      signal.TrendXValues,
+		"yvals":      signal.TrendYValues,
+		"ref-xvals":  signal.ReferenceXValues,
+		"ref-yvals":  signal.ReferenceYValues,
+		"diff-yvals": signal.DiffYValues,
+	}
+}
+
+// signalUpdate returns the samples of a signal to send, or nil if there is nothing new.
+func (encoder *deltaEncoder) signalUpdate(trend structs.Trend, signal structs.TrendSignal, signals map[signalKey]sentSignal) map[string]interface{} {
+	key := signalKey{trend.Id, signal.Module, signal.Signal}
+	previous, sent := encoder.signals[key]
+	xvals, yvals := signal.TrendXValues, signal.TrendYValues
+	current := sentSignal{lastTime: math.Inf(-1), settings: currentSettings(trend, signal), arrays: replacedArrays(signal)}
+	if len(xvals) > 0 {
+		current.lastTime = xvals[len(xvals)-1]
+	}
+	signals[key] = current
+
+	update := map[string]interface{}{
+		"id":     trend.Id,
+		"module": signal.Module,
+		"signal": signal.Signal,
+		"append": false,
+	}
+	changed := false
+	canAppend := sent && trend.PlotType == "trend" && previous.settings == current.settings &&
+		len(xvals) > 0 && xvals[0] <= previous.lastTime && previous.lastTime <= current.lastTime
+	if canAppend {
+		first := len(xvals)
+		for first > 0 && xvals[first-1] > previous.lastTime {
+			first--
+		}
+		if first < len(xvals) {
+			update["append"] = true
+			update["xvals"] = finiteSamples(xvals[first:])
+			update["yvals"] = finiteSamples(yvals[first:])
+			changed = true
+		}
+	} else if !sent || !reflect.DeepEqual(previous.arrays["xvals"], xvals) || !reflect.DeepEqual(previous.arrays["yvals"], yvals) {
+		update["xvals"] = finiteSamples(xvals)
+		update["yvals"] = finiteSamples(yvals)
+		changed = true
+	}
+
+	for name, values := range current.arrays {
+		if name == "xvals" || name == "yvals" {
+			continue
+		}
+		if !sent || !reflect.DeepEqual(previous.arrays[name], values) {
+			update[name] = finiteSamples(values)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return update
+}
+
+func (encoder *deltaEncoder) trendData(trends []structs.Trend) []interface{} {
+	signals := map[signalKey]sentSignal{}
+	var data []interface{}
+	for _, trend := range trends {
+		for _, signal := range trend.TrendSignals {
+			if update := encoder.signalUpdate(trend, signal, signals); update != nil {
+				data = append(data, update)
+			}
+		}
+	}
+	encoder.signals = signals
+	return data
+}
+
+// encode returns the message to send for a response: a snapshot the first time, and deltas afterwards.
+func (encoder *deltaEncoder) encode(response structs.JsonResponse) map[string]interface{} {
+	trends := response.Trends
+	response.Trends = withoutSamples(trends)
+	fields := toMap(response)
+
+	if encoder.last == nil {
+		encoder.trendData(trends)
+		encoder.last = fields
+		snapshot := toMap(structs.JsonResponse{Trends: trends})
+		for name, value := range fields {
+			if name != "trends" {
+				snapshot[name] = value
+			}
+		}
+		snapshot["update"] = "snapshot"
+		return snapshot
+	}
+
+	changed := map[string]interface{}{}
+	for name, value := range fields {
+		if !reflect.DeepEqual(encoder.last[name], value) {
+			changed[name] = value
+		}
+	}
+	removed := []string{}
+	for name := range encoder.last {
+		if _, exists := fields[name]; !exists {
+			removed = append(removed, name)
+		}
+	}
+	encoder.last = fields
+
+	delta := map[string]interface{}{"update": "delta"}
+	if len(changed) > 0 {
+		delta["changed"] = changed
+	}
+	if len(removed) > 0 {
+		delta["removed"] = removed
+	}
+	if data := encoder.trendData(trends); len(data) > 0 {
+		delta["trend-data"] = data
+	}
+	return delta
+}
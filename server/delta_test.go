@@ -0,0 +1,123 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/structs"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func testResponse() structs.JsonResponse {
+	scale := 2.0
+	return structs.JsonResponse{
+		Loaded:         true,
+		Status:         "play",
+		SimulationTime: 1.5,
+		Module: structs.Module{
+			Name:    "Engine",
+			Signals: []structs.Signal{{Name: "speed", Causality: "output", Type: "Real", Value: 3.0}},
+		},
+		Trends: []structs.Trend{{
+			Id:       1,
+			PlotType: "trend",
+			Label:    "Time series #1",
+			Spec:     structs.TrendSpec{Range: 10, Auto: true},
+			TrendSignals: []structs.TrendSignal{{
+				Module:       "Engine",
+				Signal:       "speed",
+				Type:         "Real",
+				Scale:        &scale,
+				TrendXValues: []float64{0, 0.5, 1},
+				TrendYValues: []float64{1, 2, 3},
+			}},
+		}},
+		DerivedSignals: []structs.DerivedSignal{},
+	}
+}
+
+func TestToMapMatchesJsonEncoding(t *testing.T) {
+	response := testResponse()
+	bytes, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want map[string]interface{}
+	if err := json.Unmarshal(bytes, &want); err != nil {
+		t.Fatal(err)
+	}
+	if got := toMap(response); !reflect.DeepEqual(got, want) {
+		t.Errorf("toMap(response) = %v, want %v", got, want)
+	}
+}
+
+func TestToMapNonFinite(t *testing.T) {
+	response := testResponse()
+	response.TotalAverageRealTimeFactor = math.Inf(1)
+	response.Module.Signals[0].Value = math.NaN()
+	response.Trends[0].TrendSignals[0].TrendYValues[1] = math.Inf(-1)
+
+	fields := toMap(response)
+	if fields["totalAverageRealTimeFactor"] != nil {
+		t.Errorf("got real time factor %v, want nil", fields["totalAverageRealTimeFactor"])
+	}
+	signal := fields["module"].(map[string]interface{})["signals"].([]interface{})[0].(map[string]interface{})
+	if signal["value"] != nil {
+		t.Errorf("got signal value %v, want nil", signal["value"])
+	}
+	if _, err := json.Marshal(fields); err != nil {
+		t.Errorf("could not encode the converted response: %v", err)
+	}
+}
+
+func trendDataOf(t *testing.T, message map[string]interface{}) map[string]interface{} {
+	data, ok := message["trend-data"].([]interface{})
+	if !ok || len(data) != 1 {
+		t.Fatalf("got trend data %v, want one signal", message["trend-data"])
+	}
+	return data[0].(map[string]interface{})
+}
+
+func TestEncodeDelta(t *testing.T) {
+	encoder := newDeltaEncoder()
+	response := testResponse()
+	if snapshot := encoder.encode(response); snapshot["update"] != "snapshot" {
+		t.Fatalf("got update %v, want snapshot", snapshot["update"])
+	}
+
+	signal := &response.Trends[0].TrendSignals[0]
+	signal.TrendXValues = []float64{0.5, 1, 1.5}
+	signal.TrendYValues = []float64{2, 3, math.NaN()}
+	update := trendDataOf(t, encoder.encode(response))
+	if update["append"] != true {
+		t.Errorf("got append %v, want true", update["append"])
+	}
+	if !reflect.DeepEqual(update["yvals"], []interface{}{nil}) {
+		t.Errorf("got appended yvals %v, want [nil]", update["yvals"])
+	}
+	if _, err := json.Marshal(update); err != nil {
+		t.Errorf("could not encode the update: %v", err)
+	}
+
+	tests := map[string]func(signal *structs.TrendSignal){
+		"scale":     func(signal *structs.TrendSignal) { scale := 3.0; signal.Scale = &scale },
+		"offset":    func(signal *structs.TrendSignal) { signal.Offset = 1 },
+		"reference": func(signal *structs.TrendSignal) { signal.Reference = true },
+	}
+	for name, change := range tests {
+		signal.TrendXValues = append(signal.TrendXValues[1:], signal.TrendXValues[len(signal.TrendXValues)-1]+0.5)
+		signal.TrendYValues = []float64{1, 2, 3}
+		change(signal)
+		update := trendDataOf(t, encoder.encode(response))
+		if update["append"] != false {
+			t.Errorf("got append %v after changing the %s, want false", update["append"], name)
+		}
+		if !reflect.DeepEqual(update["xvals"], signal.TrendXValues) {
+			t.Errorf("got xvals %v after changing the %s, want %v", update["xvals"], name, signal.TrendXValues)
+		}
+	}
+}
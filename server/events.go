@@ -0,0 +1,141 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/libcosim"
+	"cosim-demo-app/structs"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The /events endpoint streams the simulation as Server-Sent Events. Every connection is a client
+// with its own view, like a WebSocket connection, and gets these events:
+//
+//	state     the JSON encoded response, without the feedback
+//	feedback  the feedback of every command, also the ones issued by other clients
+//	bookmark  every new bookmark, among them the ones added when the execution fails
+//
+// The query parameters filter and configure the stream:
+//
+//	events    comma separated event types to send, all by default
+//	fields    comma separated fields of the state to send, like time,executionState, all by default
+//	trend     id of the trend to send samples for
+//	interval  update interval in milliseconds
+
+const eventsKeepAlive = 15 * time.Second
+
+var eventTypes = []string{"state", "feedback", "bookmark"}
+
+func queryList(r *http.Request, name string, all []string) map[string]bool {
+	list := map[string]bool{}
+	value := r.URL.Query().Get(name)
+	if len(value) == 0 {
+		for _, item := range all {
+			list[item] = true
+		}
+		return list
+	}
+	for _, item := range strings.Split(value, ",") {
+		list[strings.TrimSpace(item)] = true
+	}
+	return list
+}
+
+func writeEvent(w http.ResponseWriter, event string, data interface{}) error {
+	bytes, err := json.Marshal(jsonValue(reflect.ValueOf(data)))
+	if err != nil {
+		log.Println("Could not encode", event, "event:", err)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, bytes)
+	return err
+}
+
+// stateFields returns the fields of the state to send, or all of them if fields is empty.
+func stateFields(response structs.JsonResponse, fields map[string]bool) interface{} {
+	response.Feedback = nil
+	if len(fields) == 0 {
+		return response
+	}
+	selected := map[string]interface{}{}
+	for name, value := range toMap(response) {
+		if fields[name] {
+			selected[name] = value
+		}
+	}
+	return selected
+}
+
+func EventsHandler(command chan structs.ClientCommand, clients *libcosim.Clients) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		events := queryList(r, "events", eventTypes)
+		fields := queryList(r, "fields", nil)
+
+		clientId, state := clients.Register()
+		defer clients.Unregister(clientId)
+		clients.ShareFeedback(clientId)
+		if trend := r.URL.Query().Get("trend"); len(trend) > 0 {
+			command <- structs.ClientCommand{ClientId: clientId, Command: []string{"active-trend", trend}}
+		}
+		if interval := r.URL.Query().Get("interval"); len(interval) > 0 {
+			command <- structs.ClientCommand{ClientId: clientId, Command: []string{"set-update-interval", interval}}
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(eventsKeepAlive)
+		defer keepAlive.Stop()
+		var bookmarks map[int]bool
+		for {
+			var err error
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepAlive.C:
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			case response, open := <-state:
+				if !open {
+					return
+				}
+				if events["feedback"] && response.Feedback != nil {
+					err = writeEvent(w, "feedback", response.Feedback)
+				}
+				// The bookmarks present when connecting are not sent as events.
+				seen := map[int]bool{}
+				for _, bookmark := range response.Bookmarks {
+					seen[bookmark.Id] = true
+					if err == nil && events["bookmark"] && bookmarks != nil && !bookmarks[bookmark.Id] {
+						err = writeEvent(w, "bookmark", bookmark)
+					}
+				}
+				bookmarks = seen
+				if err == nil && events["state"] {
+					err = writeEvent(w, "state", stateFields(response, fields))
+				}
+			}
+			if err != nil {
+				log.Println("write error:", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
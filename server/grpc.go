@@ -0,0 +1,255 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"context"
+	"cosim-demo-app/cosimapi"
+	"cosim-demo-app/libcosim"
+	"cosim-demo-app/structs"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"log"
+	"net"
+	"reflect"
+	"strconv"
+)
+
+// The gRPC service executes its RPCs as commands of a client of its own, registered for each call,
+// so they are handled by the command loop exactly like the commands sent over the WebSocket.
+// It is only started when an address to listen on is given.
+
+type grpcServer struct {
+	cosimapi.UnimplementedCosimServer
+	command chan structs.ClientCommand
+	clients *libcosim.Clients
+	status  *structs.SimulationStatus
+	sim     *libcosim.Simulation
+}
+
+func GrpcServer(address string, command chan structs.ClientCommand, clients *libcosim.Clients, simulationStatus *structs.SimulationStatus, sim *libcosim.Simulation) {
+	if len(address) == 0 {
+		return
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Println("Could not start the gRPC server:", err)
+		return
+	}
+	server := grpc.NewServer()
+	cosimapi.RegisterCosimServer(server, &grpcServer{command: command, clients: clients, status: simulationStatus, sim: sim})
+	log.Println("gRPC server listening on", address)
+	if err := server.Serve(listener); err != nil {
+		log.Println("gRPC server stopped:", err)
+	}
+}
+
+func (s *grpcServer) execute(ctx context.Context, cmd ...string) (structs.JsonResponse, error) {
+	return runCommand(ctx, s.command, s.clients, cmd...)
+}
+
+func commandReply(feedback *structs.CommandFeedback) *cosimapi.CommandReply {
+	return &cosimapi.CommandReply{Success: feedback.Success, Message: feedback.Message, Command: feedback.Command}
+}
+
+func (s *grpcServer) run(ctx context.Context, cmd ...string) (*cosimapi.CommandReply, error) {
+	response, err := s.execute(ctx, cmd...)
+	if err != nil {
+		return nil, err
+	}
+	return commandReply(response.Feedback), nil
+}
+
+func (s *grpcServer) variableArguments(variable *cosimapi.VariableRef) ([]string, error) {
+	args, err := variableArguments(s.sim, s.status, variable.GetModule(), variable.GetVariable())
+	if err == libcosim.ErrNotLoaded {
+		return nil, grpcstatus.Error(codes.FailedPrecondition, err.Error())
+	} else if err != nil {
+		return nil, grpcstatus.Error(codes.NotFound, err.Error())
+	}
+	return args, nil
+}
+
+func (s *grpcServer) Load(ctx context.Context, request *cosimapi.LoadRequest) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "load", request.GetConfigPath(), request.GetLogDir())
+}
+
+func (s *grpcServer) Teardown(ctx context.Context, _ *cosimapi.Empty) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "teardown")
+}
+
+func (s *grpcServer) Play(ctx context.Context, _ *cosimapi.Empty) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "play")
+}
+
+func (s *grpcServer) Pause(ctx context.Context, _ *cosimapi.Empty) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "pause")
+}
+
+func (s *grpcServer) SetValue(ctx context.Context, request *cosimapi.SetValueRequest) (*cosimapi.CommandReply, error) {
+	args, err := s.variableArguments(request.GetVariable())
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, append([]string{"set-value"}, append(args, request.GetValue())...)...)
+}
+
+func (s *grpcServer) ResetValue(ctx context.Context, request *cosimapi.VariableRef) (*cosimapi.CommandReply, error) {
+	args, err := s.variableArguments(request)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, append([]string{"reset-value"}, args...)...)
+}
+
+func (s *grpcServer) ListTrends(ctx context.Context, _ *cosimapi.Empty) (*cosimapi.TrendList, error) {
+	response, err := s.execute(ctx, "get-module-data")
+	if err != nil {
+		return nil, err
+	}
+	list := &cosimapi.TrendList{}
+	for _, trend := range response.Trends {
+		t := &cosimapi.Trend{Id: int32(trend.Id), PlotType: trend.PlotType, Label: trend.Label}
+		for _, signal := range trend.TrendSignals {
+			t.Signals = append(t.Signals, &cosimapi.TrendSignalRef{Module: signal.Module, Signal: signal.Signal, Axis: signal.Axis})
+		}
+		list.Trends = append(list.Trends, t)
+	}
+	return list, nil
+}
+
+func (s *grpcServer) NewTrend(ctx context.Context, request *cosimapi.NewTrendRequest) (*cosimapi.NewTrendReply, error) {
+	response, err := s.execute(ctx, "newtrend", request.GetPlotType(), request.GetLabel())
+	if err != nil {
+		return nil, err
+	}
+	reply := &cosimapi.NewTrendReply{Reply: commandReply(response.Feedback)}
+	if response.Feedback.Success {
+		reply.Id = int32(response.Feedback.TrendId)
+	}
+	return reply, nil
+}
+
+func (s *grpcServer) RemoveTrend(ctx context.Context, request *cosimapi.TrendRef) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "removetrend", strconv.Itoa(int(request.GetId())))
+}
+
+func (s *grpcServer) AddToTrend(ctx context.Context, request *cosimapi.TrendSignalRequest) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "addtotrend", request.GetModule(), request.GetSignal(), strconv.Itoa(int(request.GetTrendId())), request.GetAxis())
+}
+
+func (s *grpcServer) RemoveFromTrend(ctx context.Context, request *cosimapi.TrendSignalRequest) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "removefromtrend", strconv.Itoa(int(request.GetTrendId())), request.GetModule(), request.GetSignal())
+}
+
+func (s *grpcServer) ListScenarios(ctx context.Context, _ *cosimapi.Empty) (*cosimapi.ScenarioList, error) {
+	response, err := s.execute(ctx, "get-module-data")
+	if err != nil {
+		return nil, err
+	}
+	list := &cosimapi.ScenarioList{}
+	if response.Scenarios != nil {
+		list.FileNames = *response.Scenarios
+	}
+	return list, nil
+}
+
+func (s *grpcServer) LoadScenario(ctx context.Context, request *cosimapi.ScenarioRef) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "load-scenario", request.GetFileName())
+}
+
+func (s *grpcServer) AbortScenario(ctx context.Context, _ *cosimapi.Empty) (*cosimapi.CommandReply, error) {
+	return s.run(ctx, "abort-scenario")
+}
+
+// stream registers a client for a server-streaming RPC and calls send with every state update.
+func (s *grpcServer) stream(ctx context.Context, interval int32, send func(structs.JsonResponse) error) error {
+	clientId, state := s.clients.Register()
+	defer s.clients.Unregister(clientId)
+	if interval > 0 {
+		s.command <- structs.ClientCommand{ClientId: clientId, Command: []string{"set-update-interval", strconv.Itoa(int(interval))}}
+	}
+	for {
+		select {
+		case response := <-state:
+			if response.Feedback != nil && !response.Feedback.Success {
+				return grpcstatus.Error(codes.InvalidArgument, response.Feedback.Message)
+			}
+			if err := send(response); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *grpcServer) StreamExecutionStatus(request *cosimapi.StreamRequest, server cosimapi.Cosim_StreamExecutionStatusServer) error {
+	return s.stream(server.Context(), request.GetInterval(), func(response structs.JsonResponse) error {
+		return server.Send(&cosimapi.ExecutionStatus{
+			Loaded:                       response.Loaded,
+			State:                        response.ExecutionState,
+			Time:                         response.SimulationTime,
+			TotalAverageRealTimeFactor:   response.TotalAverageRealTimeFactor,
+			RollingAverageRealTimeFactor: response.RollingAverageRealTimeFactor,
+			RealTimeFactorTarget:         response.RealTimeFactorTarget,
+			RealTime:                     response.IsRealTimeSimulation,
+			RunningScenario:              response.RunningScenario,
+			LastErrorCode:                response.LastErrorCode,
+			LastErrorMessage:             response.LastErrorMessage,
+		})
+	})
+}
+
+func signalValue(module string, signal structs.Signal) (*cosimapi.SignalValue, error) {
+	value := &cosimapi.SignalValue{Module: module, Variable: signal.Name}
+	v := reflect.ValueOf(signal.Value)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		value.Value = &cosimapi.SignalValue_Real{Real: v.Float()}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		value.Value = &cosimapi.SignalValue_Integer{Integer: v.Int()}
+	case reflect.Bool:
+		value.Value = &cosimapi.SignalValue_Boolean{Boolean: v.Bool()}
+	case reflect.String:
+		value.Value = &cosimapi.SignalValue_String_{String_: v.String()}
+	default:
+		return nil, errors.New("Unsupported value type of " + module + "." + signal.Name)
+	}
+	return value, nil
+}
+
+func (s *grpcServer) StreamSignalValues(request *cosimapi.SignalValuesRequest, server cosimapi.Cosim_StreamSignalValuesServer) error {
+	if len(request.GetVariables()) == 0 {
+		return grpcstatus.Error(codes.InvalidArgument, "No variables given")
+	}
+	var pairs []string
+	for _, variable := range request.GetVariables() {
+		pairs = append(pairs, variable.GetModule(), variable.GetVariable())
+	}
+	return s.stream(server.Context(), request.GetInterval(), func(response structs.JsonResponse) error {
+		if !response.Loaded {
+			return nil
+		}
+		modules, err := libcosim.VariableValues(s.sim, s.status, pairs)
+		if err != nil {
+			return grpcstatus.Error(codes.NotFound, err.Error())
+		}
+		values := &cosimapi.SignalValues{Time: response.SimulationTime}
+		for _, module := range modules {
+			for _, signal := range module.Signals {
+				value, err := signalValue(module.Name, signal)
+				if err != nil {
+					log.Println(err.Error())
+					continue
+				}
+				values.Values = append(values.Values, value)
+			}
+		}
+		return server.Send(values)
+	})
+}
@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/libcosim"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// /healthz reports whether the command loop is responsive, that is it went around within the
+// timeout, in seconds, given by the query parameter timeout. It goes around at least every 250 ms
+// when idle, but loading a large system can keep it busy for a while, hence the generous default.
+//
+// /readyz reports whether a simulation is loaded, its execution has not failed, and for real time
+// simulations, the rolling average real time factor is within tolerance of the target. The query
+// parameter tolerance gives the allowed deviation relative to the target.
+//
+// Both answer 200 when the check passes and 503 when it doesn't, with JSON diagnostics. They read
+// the execution metrics the command loop caches, so a stuck libcosim call can't block them.
+
+const (
+	healthDefaultTimeout  = 30 * time.Second
+	readyDefaultTolerance = 0.1
+	executionErrorState   = "COSIM_EXECUTION_ERROR"
+	executionRunningState = "COSIM_EXECUTION_RUNNING"
+)
+
+type healthReport struct {
+	Healthy          bool    `json:"healthy"`
+	CommandLoopIdle  float64 `json:"commandLoopIdle"`
+	Timeout          float64 `json:"timeout"`
+	LastErrorCode    string  `json:"lastErrorCode"`
+	LastErrorMessage string  `json:"lastErrorMessage"`
+}
+
+type readyReport struct {
+	Ready                        bool     `json:"ready"`
+	Reasons                      []string `json:"reasons"`
+	Loaded                       bool     `json:"loaded"`
+	ExecutionState               string   `json:"executionState,omitempty"`
+	SimulationTime               float64  `json:"simulationTime"`
+	RealTime                     bool     `json:"realTime"`
+	RollingAverageRealTimeFactor float64  `json:"rollingAverageRealTimeFactor"`
+	RealTimeFactorTarget         float64  `json:"realTimeFactorTarget"`
+	Tolerance                    float64  `json:"tolerance"`
+	LastErrorCode                string   `json:"lastErrorCode"`
+	LastErrorMessage             string   `json:"lastErrorMessage"`
+}
+
+// queryFloat returns a positive number from a query parameter, or the default if it is absent or invalid.
+func queryFloat(r *http.Request, name string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(r.URL.Query().Get(name), 64)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func writeProbe(w http.ResponseWriter, passed bool, report interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if !passed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+func HealthHandler(metrics *libcosim.Metrics) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		timeout := queryFloat(r, "timeout", healthDefaultTimeout.Seconds())
+		idle := metrics.CommandLoopIdle().Seconds()
+		execution := metrics.Execution()
+		report := healthReport{
+			Healthy:          idle <= timeout,
+			CommandLoopIdle:  idle,
+			Timeout:          timeout,
+			LastErrorCode:    execution.LastErrorCode,
+			LastErrorMessage: execution.LastErrorMessage,
+		}
+		writeProbe(w, report.Healthy, report)
+	}
+}
+
+func ReadyHandler(metrics *libcosim.Metrics) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tolerance := queryFloat(r, "tolerance", readyDefaultTolerance)
+		execution := metrics.Execution()
+		report := readyReport{
+			Reasons:                      []string{},
+			Loaded:                       execution.Loaded,
+			ExecutionState:               execution.State,
+			SimulationTime:               execution.Time,
+			RealTime:                     execution.RealTime,
+			RollingAverageRealTimeFactor: execution.RollingAverageRealTimeFactor,
+			RealTimeFactorTarget:         execution.RealTimeFactorTarget,
+			Tolerance:                    tolerance,
+			LastErrorCode:                execution.LastErrorCode,
+			LastErrorMessage:             execution.LastErrorMessage,
+		}
+		if !execution.Loaded {
+			report.Reasons = append(report.Reasons, "No simulation is loaded")
+		}
+		if execution.State == executionErrorState {
+			report.Reasons = append(report.Reasons, "The execution has failed")
+		}
+		if execution.RealTime && execution.State == executionRunningState && execution.RealTimeFactorTarget > 0 &&
+			math.Abs(execution.RollingAverageRealTimeFactor-execution.RealTimeFactorTarget) > tolerance*execution.RealTimeFactorTarget {
+			report.Reasons = append(report.Reasons, "The real time factor is off target by more than the tolerance")
+		}
+		report.Ready = len(report.Reasons) == 0
+		writeProbe(w, report.Ready, report)
+	}
+}
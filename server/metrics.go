@@ -0,0 +1,96 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"bufio"
+	"cosim-demo-app/libcosim"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+)
+
+// The /metrics endpoint serves the metrics in the Prometheus text format, version 0.0.4.
+
+// websocketConnections is the number of connected WebSocket clients.
+var websocketConnections int64
+
+func formatMetric(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func writeMetricHeader(w *bufio.Writer, name string, metricType string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeGauge(w *bufio.Writer, name string, help string, value float64) {
+	writeMetricHeader(w, name, "gauge", help)
+	fmt.Fprintf(w, "%s %s\n", name, formatMetric(value))
+}
+
+// writeHistogram writes the samples of a histogram, with labels like command="play" or none.
+func writeHistogram(w *bufio.Writer, name string, labels string, timings libcosim.Timings) {
+	separator := ""
+	if len(labels) > 0 {
+		separator = ","
+	}
+	var cumulative uint64
+	for i, bound := range libcosim.TimingBuckets {
+		if i < len(timings.Buckets) {
+			cumulative += timings.Buckets[i]
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, separator, formatMetric(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, separator, timings.Count)
+	if len(labels) > 0 {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatMetric(timings.Sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, timings.Count)
+}
+
+func boolMetric(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func MetricsHandler(metrics *libcosim.Metrics) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		execution := metrics.Execution()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		out := bufio.NewWriter(w)
+		defer out.Flush()
+
+		writeGauge(out, "cosim_loaded", "Whether a simulation is loaded.", boolMetric(execution.Loaded))
+		writeGauge(out, "cosim_simulation_time_seconds", "Current simulation time.", execution.Time)
+		writeGauge(out, "cosim_real_time_factor_total_average", "Total average real time factor.", execution.TotalAverageRealTimeFactor)
+		writeGauge(out, "cosim_real_time_factor_rolling_average", "Rolling average real time factor.", execution.RollingAverageRealTimeFactor)
+		writeGauge(out, "cosim_real_time_factor_target", "Real time factor target.", execution.RealTimeFactorTarget)
+		writeMetricHeader(out, "cosim_execution_state", "gauge", "Execution state, 1 for the current state.")
+		for _, state := range libcosim.ExecutionStates {
+			fmt.Fprintf(out, "cosim_execution_state{state=%q} %s\n", state, formatMetric(boolMetric(state == execution.State)))
+		}
+		writeGauge(out, "cosim_slaves", "Number of simulators in the loaded simulation.", float64(execution.Slaves))
+		writeGauge(out, "cosim_overrides", "Number of overridden variables.", float64(execution.Overrides))
+		writeGauge(out, "cosim_websocket_clients", "Number of connected WebSocket clients.", float64(atomic.LoadInt64(&websocketConnections)))
+
+		commands := metrics.CommandTimings()
+		var names []string
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writeMetricHeader(out, "cosim_command_duration_seconds", "histogram", "Time to execute commands and send the responses, by command.")
+		for _, name := range names {
+			writeHistogram(out, "cosim_command_duration_seconds", fmt.Sprintf("command=%q", name), commands[name])
+		}
+		writeMetricHeader(out, "cosim_state_broadcast_duration_seconds", "histogram", "Time to send the state updates due to the clients.")
+		writeHistogram(out, "cosim_state_broadcast_duration_seconds", "", metrics.BroadcastTimings())
+	}
+}
@@ -0,0 +1,527 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/libcosim"
+	"cosim-demo-app/structs"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// The Modbus TCP server is configured per simulation with a modbus.json file in the configuration folder:
+//
+//	{
+//	  "address": ":5020",
+//	  "holdingRegisters": [{"address": 0, "module": "Engine", "variable": "throttle", "type": "int16", "scale": 100}],
+//	  "inputRegisters": [{"address": 0, "module": "Engine", "variable": "speed", "type": "float32"}],
+//	  "coils": [{"address": 0, "module": "Engine", "variable": "ignition"}],
+//	  "discreteInputs": [{"address": 0, "module": "Engine", "variable": "running"}]
+//	}
+//
+// Register types are int16 (the default), uint16, int32, uint32 and float32. The 32 bit types take
+// two registers, high word first. A register holds the value multiplied by scale, rounded for the
+// integer types. Values are read from the last value observer, and writes to holding registers and
+// coils become overrides. Unmapped addresses read as zero, writing to them is an illegal data address.
+
+const (
+	modbusConfigFile     = "modbus.json"
+	modbusDefaultAddress = ":5020"
+	modbusMaxPdu         = 253
+)
+
+const (
+	modbusReadCoils              = 0x01
+	modbusReadDiscreteInputs     = 0x02
+	modbusReadHoldingRegisters   = 0x03
+	modbusReadInputRegisters     = 0x04
+	modbusWriteSingleCoil        = 0x05
+	modbusWriteSingleRegister    = 0x06
+	modbusWriteMultipleCoils     = 0x0F
+	modbusWriteMultipleRegisters = 0x10
+)
+
+const (
+	modbusIllegalFunction    = 0x01
+	modbusIllegalDataAddress = 0x02
+	modbusIllegalDataValue   = 0x03
+	modbusDeviceFailure      = 0x04
+)
+
+type modbusMapping struct {
+	Address  uint16  `json:"address"`
+	Module   string  `json:"module"`
+	Variable string  `json:"variable"`
+	Type     string  `json:"type,omitempty"`
+	Scale    float64 `json:"scale,omitempty"`
+}
+
+type modbusConfig struct {
+	Address          string          `json:"address"`
+	HoldingRegisters []modbusMapping `json:"holdingRegisters"`
+	InputRegisters   []modbusMapping `json:"inputRegisters"`
+	Coils            []modbusMapping `json:"coils"`
+	DiscreteInputs   []modbusMapping `json:"discreteInputs"`
+}
+
+// modbusEntry is the mapping of an address, and the number of the register within the mapping.
+type modbusEntry struct {
+	mapping *modbusMapping
+	word    int
+}
+
+type modbusTable map[uint16]modbusEntry
+
+type modbusServer struct {
+	command          chan structs.ClientCommand
+	sim              *libcosim.Simulation
+	status           *structs.SimulationStatus
+	listener         net.Listener
+	holdingRegisters modbusTable
+	inputRegisters   modbusTable
+	coils            modbusTable
+	discreteInputs   modbusTable
+	mutex            sync.Mutex
+	connections      map[net.Conn]bool
+	// read reads the values of variables given as module and variable name pairs.
+	read func(pairs []string) ([]structs.Module, error)
+}
+
+func registerWords(registerType string) (int, error) {
+	switch registerType {
+	case "", "int16", "uint16":
+		return 1, nil
+	case "int32", "uint32", "float32":
+		return 2, nil
+	}
+	return 0, errors.New("Unknown register type " + registerType)
+}
+
+func newModbusTable(mappings []modbusMapping, registers bool) (modbusTable, error) {
+	table := modbusTable{}
+	for i := range mappings {
+		mapping := &mappings[i]
+		words := 1
+		if registers {
+			var err error
+			if words, err = registerWords(mapping.Type); err != nil {
+				return nil, err
+			}
+			if mapping.Scale == 0 {
+				mapping.Scale = 1
+			}
+		}
+		for word := 0; word < words; word++ {
+			address := int(mapping.Address) + word
+			if _, taken := table[uint16(address)]; taken || address > math.MaxUint16 {
+				return nil, errors.New("Address " + strconv.Itoa(address) + " of " + mapping.Module + "." + mapping.Variable + " is already mapped or out of range")
+			}
+			table[uint16(address)] = modbusEntry{mapping, word}
+		}
+	}
+	return table, nil
+}
+
+// ModbusServer serves the variables of the loaded simulation over Modbus TCP, if it has a Modbus configuration.
+func ModbusServer(command chan structs.ClientCommand, simulationStatus *structs.SimulationStatus, sim *libcosim.Simulation) {
+	followSimulation(sim, simulationStatus, func(configDir string) func() {
+		config := modbusConfig{Address: modbusDefaultAddress}
+		if err := readSimulationConfig(configDir, modbusConfigFile, &config); os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			log.Println("Could not read Modbus configuration:", err)
+			return nil
+		}
+		server, err := openModbusServer(config, command, simulationStatus, sim)
+		if err != nil {
+			log.Println("Could not start Modbus server:", err)
+			return nil
+		}
+		return server.close
+	})
+}
+
+func openModbusServer(config modbusConfig, command chan structs.ClientCommand, status *structs.SimulationStatus, sim *libcosim.Simulation) (*modbusServer, error) {
+	server := &modbusServer{command: command, sim: sim, status: status, connections: map[net.Conn]bool{}}
+	server.read = func(pairs []string) ([]structs.Module, error) {
+		return libcosim.VariableValues(sim, status, pairs)
+	}
+	var err error
+	if server.holdingRegisters, err = newModbusTable(config.HoldingRegisters, true); err != nil {
+		return nil, err
+	}
+	if server.inputRegisters, err = newModbusTable(config.InputRegisters, true); err != nil {
+		return nil, err
+	}
+	if server.coils, err = newModbusTable(config.Coils, false); err != nil {
+		return nil, err
+	}
+	if server.discreteInputs, err = newModbusTable(config.DiscreteInputs, false); err != nil {
+		return nil, err
+	}
+	if server.listener, err = net.Listen("tcp", config.Address); err != nil {
+		return nil, err
+	}
+	log.Println("Modbus server listening on", config.Address)
+	go server.accept()
+	return server, nil
+}
+
+func (server *modbusServer) close() {
+	server.listener.Close()
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	for conn := range server.connections {
+		conn.Close()
+	}
+	log.Println("Stopped Modbus server")
+}
+
+func (server *modbusServer) accept() {
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		server.mutex.Lock()
+		server.connections[conn] = true
+		server.mutex.Unlock()
+		go server.serve(conn)
+	}
+}
+
+// serve answers the requests of a connection, one at a time.
+func (server *modbusServer) serve(conn net.Conn) {
+	defer func() {
+		server.mutex.Lock()
+		delete(server.connections, conn)
+		server.mutex.Unlock()
+		conn.Close()
+	}()
+	header := make([]byte, 7)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		length := int(binary.BigEndian.Uint16(header[4:6]))
+		if binary.BigEndian.Uint16(header[2:4]) != 0 || length < 2 || length > modbusMaxPdu+1 {
+			log.Println("Invalid Modbus request header from", conn.RemoteAddr())
+			return
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+		reply := server.handle(pdu)
+		frame := make([]byte, 7, 7+len(reply))
+		copy(frame, header[:4])
+		binary.BigEndian.PutUint16(frame[4:6], uint16(len(reply)+1))
+		frame[6] = header[6]
+		if _, err := conn.Write(append(frame, reply...)); err != nil {
+			return
+		}
+	}
+}
+
+func modbusException(function byte, code byte) []byte {
+	return []byte{function | 0x80, code}
+}
+
+// handle executes a request and returns the reply.
+func (server *modbusServer) handle(pdu []byte) []byte {
+	function := pdu[0]
+	data := pdu[1:]
+	if _, err := libcosim.LoadedMetaData(server.sim, server.status); err != nil {
+		return modbusException(function, modbusDeviceFailure)
+	}
+	switch function {
+	case modbusReadCoils, modbusReadDiscreteInputs, modbusReadHoldingRegisters, modbusReadInputRegisters:
+		if len(data) != 4 {
+			return modbusException(function, modbusIllegalDataValue)
+		}
+		start := binary.BigEndian.Uint16(data[0:2])
+		quantity := binary.BigEndian.Uint16(data[2:4])
+		if int(start)+int(quantity) > math.MaxUint16+1 {
+			return modbusException(function, modbusIllegalDataAddress)
+		}
+		switch function {
+		case modbusReadCoils:
+			return server.readBits(function, server.coils, start, quantity)
+		case modbusReadDiscreteInputs:
+			return server.readBits(function, server.discreteInputs, start, quantity)
+		case modbusReadHoldingRegisters:
+			return server.readRegisters(function, server.holdingRegisters, start, quantity)
+		default:
+			return server.readRegisters(function, server.inputRegisters, start, quantity)
+		}
+	case modbusWriteSingleCoil:
+		if len(data) != 4 {
+			return modbusException(function, modbusIllegalDataValue)
+		}
+		value := binary.BigEndian.Uint16(data[2:4])
+		if value != 0xFF00 && value != 0x0000 {
+			return modbusException(function, modbusIllegalDataValue)
+		}
+		if code := server.writeBits(binary.BigEndian.Uint16(data[0:2]), []bool{value == 0xFF00}); code != 0 {
+			return modbusException(function, code)
+		}
+		return pdu
+	case modbusWriteSingleRegister:
+		if len(data) != 4 {
+			return modbusException(function, modbusIllegalDataValue)
+		}
+		if code := server.writeRegisters(binary.BigEndian.Uint16(data[0:2]), []uint16{binary.BigEndian.Uint16(data[2:4])}); code != 0 {
+			return modbusException(function, code)
+		}
+		return pdu
+	case modbusWriteMultipleCoils:
+		if len(data) < 5 {
+			return modbusException(function, modbusIllegalDataValue)
+		}
+		quantity := int(binary.BigEndian.Uint16(data[2:4]))
+		if quantity < 1 || quantity > 1968 || int(data[4]) != (quantity+7)/8 || len(data) != 5+int(data[4]) {
+			return modbusException(function, modbusIllegalDataValue)
+		}
+		values := make([]bool, quantity)
+		for i := range values {
+			values[i] = data[5+i/8]&(1<<uint(i%8)) != 0
+		}
+		if code := server.writeBits(binary.BigEndian.Uint16(data[0:2]), values); code != 0 {
+			return modbusException(function, code)
+		}
+		return pdu[:5]
+	case modbusWriteMultipleRegisters:
+		if len(data) < 5 {
+			return modbusException(function, modbusIllegalDataValue)
+		}
+		quantity := int(binary.BigEndian.Uint16(data[2:4]))
+		if quantity < 1 || quantity > 123 || int(data[4]) != 2*quantity || len(data) != 5+2*quantity {
+			return modbusException(function, modbusIllegalDataValue)
+		}
+		values := make([]uint16, quantity)
+		for i := range values {
+			values[i] = binary.BigEndian.Uint16(data[5+2*i:])
+		}
+		if code := server.writeRegisters(binary.BigEndian.Uint16(data[0:2]), values); code != 0 {
+			return modbusException(function, code)
+		}
+		return pdu[:5]
+	}
+	return modbusException(function, modbusIllegalFunction)
+}
+
+// values reads the variables mapped to the addresses from start to start + quantity.
+func (server *modbusServer) values(table modbusTable, start uint16, quantity uint16) (map[string]interface{}, error) {
+	var pairs []string
+	added := map[*modbusMapping]bool{}
+	for i := 0; i < int(quantity); i++ {
+		if entry, mapped := table[uint16(int(start)+i)]; mapped && !added[entry.mapping] {
+			added[entry.mapping] = true
+			pairs = append(pairs, entry.mapping.Module, entry.mapping.Variable)
+		}
+	}
+	values := map[string]interface{}{}
+	if len(pairs) == 0 {
+		return values, nil
+	}
+	modules, err := server.read(pairs)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range modules {
+		for _, signal := range module.Signals {
+			values[module.Name+"."+signal.Name] = signal.Value
+		}
+	}
+	return values, nil
+}
+
+// numericValue converts the value of a variable of any type but String to a float64.
+func numericValue(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func (server *modbusServer) readBits(function byte, table modbusTable, start uint16, quantity uint16) []byte {
+	if quantity < 1 || quantity > 2000 {
+		return modbusException(function, modbusIllegalDataValue)
+	}
+	values, err := server.values(table, start, quantity)
+	if err != nil {
+		log.Println("Could not read Modbus values:", err)
+		return modbusException(function, modbusDeviceFailure)
+	}
+	reply := make([]byte, 2+(quantity+7)/8)
+	reply[0] = function
+	reply[1] = byte((quantity + 7) / 8)
+	for i := 0; i < int(quantity); i++ {
+		entry, mapped := table[uint16(int(start)+i)]
+		if !mapped {
+			continue
+		}
+		if value, ok := numericValue(values[entry.mapping.Module+"."+entry.mapping.Variable]); ok && value != 0 {
+			reply[2+i/8] |= 1 << uint(i%8)
+		}
+	}
+	return reply
+}
+
+// registerValues encodes a value in the registers of a mapping.
+func registerValues(mapping *modbusMapping, value float64) []uint16 {
+	scaled := value * mapping.Scale
+	// Integer registers can't hold NaN, and converting it is implementation-defined, so it becomes 0.
+	clamp := func(min float64, max float64) float64 {
+		if math.IsNaN(scaled) {
+			return 0
+		}
+		return math.Max(min, math.Min(max, math.Round(scaled)))
+	}
+	switch mapping.Type {
+	case "uint16":
+		return []uint16{uint16(clamp(0, math.MaxUint16))}
+	case "int32":
+		raw := uint32(int32(clamp(math.MinInt32, math.MaxInt32)))
+		return []uint16{uint16(raw >> 16), uint16(raw)}
+	case "uint32":
+		raw := uint32(clamp(0, math.MaxUint32))
+		return []uint16{uint16(raw >> 16), uint16(raw)}
+	case "float32":
+		raw := math.Float32bits(float32(scaled))
+		return []uint16{uint16(raw >> 16), uint16(raw)}
+	}
+	return []uint16{uint16(int16(clamp(math.MinInt16, math.MaxInt16)))}
+}
+
+// registerValue decodes the value of a mapping from its registers.
+func registerValue(mapping *modbusMapping, words []uint16) float64 {
+	var raw float64
+	switch mapping.Type {
+	case "uint16":
+		raw = float64(words[0])
+	case "int32":
+		raw = float64(int32(uint32(words[0])<<16 | uint32(words[1])))
+	case "uint32":
+		raw = float64(uint32(words[0])<<16 | uint32(words[1]))
+	case "float32":
+		raw = float64(math.Float32frombits(uint32(words[0])<<16 | uint32(words[1])))
+	default:
+		raw = float64(int16(words[0]))
+	}
+	return raw / mapping.Scale
+}
+
+func (server *modbusServer) readRegisters(function byte, table modbusTable, start uint16, quantity uint16) []byte {
+	if quantity < 1 || quantity > 125 {
+		return modbusException(function, modbusIllegalDataValue)
+	}
+	values, err := server.values(table, start, quantity)
+	if err != nil {
+		log.Println("Could not read Modbus values:", err)
+		return modbusException(function, modbusDeviceFailure)
+	}
+	reply := make([]byte, 2+2*quantity)
+	reply[0] = function
+	reply[1] = byte(2 * quantity)
+	for i := 0; i < int(quantity); i++ {
+		entry, mapped := table[uint16(int(start)+i)]
+		if !mapped {
+			continue
+		}
+		value, _ := numericValue(values[entry.mapping.Module+"."+entry.mapping.Variable])
+		binary.BigEndian.PutUint16(reply[2+2*i:], registerValues(entry.mapping, value)[entry.word])
+	}
+	return reply
+}
+
+// override sets a variable through the command loop, formatting the value for the type of the variable.
+func (server *modbusServer) override(mapping *modbusMapping, value float64) byte {
+	args, err := variableArguments(server.sim, server.status, mapping.Module, mapping.Variable)
+	if err != nil {
+		log.Println("Could not apply Modbus write:", err)
+		return modbusDeviceFailure
+	}
+	var formatted string
+	switch args[1] {
+	case "Real":
+		formatted = strconv.FormatFloat(value, 'g', -1, 64)
+	case "Integer":
+		formatted = strconv.Itoa(int(math.Round(value)))
+	case "Boolean":
+		formatted = strconv.FormatBool(value != 0)
+	default:
+		return modbusIllegalDataAddress
+	}
+	server.command <- structs.ClientCommand{Command: append([]string{"set-value"}, append(args, formatted)...)}
+	return 0
+}
+
+// writeBits overrides the variables mapped to consecutive coils. All addresses must be mapped.
+func (server *modbusServer) writeBits(start uint16, values []bool) byte {
+	for i := range values {
+		if int(start)+i > math.MaxUint16 {
+			return modbusIllegalDataAddress
+		}
+		if _, mapped := server.coils[uint16(int(start)+i)]; !mapped {
+			return modbusIllegalDataAddress
+		}
+	}
+	for i, value := range values {
+		numeric := 0.0
+		if value {
+			numeric = 1
+		}
+		if code := server.override(server.coils[uint16(int(start)+i)].mapping, numeric); code != 0 {
+			return code
+		}
+	}
+	return 0
+}
+
+// writeRegisters overrides the variables mapped to consecutive holding registers. All addresses
+// must be mapped, and the registers must cover whole values.
+func (server *modbusServer) writeRegisters(start uint16, values []uint16) byte {
+	for i := 0; i < len(values); {
+		if int(start)+i > math.MaxUint16 {
+			return modbusIllegalDataAddress
+		}
+		entry, mapped := server.holdingRegisters[uint16(int(start)+i)]
+		if !mapped || entry.word != 0 {
+			return modbusIllegalDataAddress
+		}
+		words, _ := registerWords(entry.mapping.Type)
+		if i+words > len(values) {
+			return modbusIllegalDataAddress
+		}
+		i += words
+	}
+	for i := 0; i < len(values); {
+		mapping := server.holdingRegisters[uint16(int(start)+i)].mapping
+		words, _ := registerWords(mapping.Type)
+		if code := server.override(mapping, registerValue(mapping, values[i:i+words])); code != 0 {
+			return code
+		}
+		i += words
+	}
+	return 0
+}
@@ -0,0 +1,170 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/libcosim"
+	"cosim-demo-app/structs"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRegisterValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping modbusMapping
+		value   float64
+		words   []uint16
+	}{
+		{"int16 rounded", modbusMapping{Scale: 1}, 1.4, []uint16{1}},
+		{"int16 negative", modbusMapping{Type: "int16", Scale: 1}, -1.6, []uint16{0xFFFE}},
+		{"int16 clamped above", modbusMapping{Scale: 1}, 40000, []uint16{0x7FFF}},
+		{"int16 clamped below", modbusMapping{Scale: 1}, -40000, []uint16{0x8000}},
+		{"int16 scaled", modbusMapping{Scale: 100}, 1.234, []uint16{123}},
+		{"uint16 clamped below", modbusMapping{Type: "uint16", Scale: 1}, -5, []uint16{0}},
+		{"uint16 clamped above", modbusMapping{Type: "uint16", Scale: 1}, 70000, []uint16{0xFFFF}},
+		{"int32 high word first", modbusMapping{Type: "int32", Scale: 1}, 70000, []uint16{0x0001, 0x1170}},
+		{"int32 negative", modbusMapping{Type: "int32", Scale: 1}, -2, []uint16{0xFFFF, 0xFFFE}},
+		{"int32 clamped", modbusMapping{Type: "int32", Scale: 1}, -1e10, []uint16{0x8000, 0x0000}},
+		{"uint32 clamped", modbusMapping{Type: "uint32", Scale: 1}, 1e10, []uint16{0xFFFF, 0xFFFF}},
+		{"float32 high word first", modbusMapping{Type: "float32", Scale: 1}, 1.5, []uint16{0x3FC0, 0x0000}},
+		{"float32 scaled", modbusMapping{Type: "float32", Scale: 2}, 1.5, []uint16{0x4040, 0x0000}},
+		{"int16 NaN", modbusMapping{Scale: 1}, math.NaN(), []uint16{0}},
+		{"uint16 NaN", modbusMapping{Type: "uint16", Scale: 1}, math.NaN(), []uint16{0}},
+		{"int32 NaN", modbusMapping{Type: "int32", Scale: 1}, math.NaN(), []uint16{0, 0}},
+		{"uint32 NaN", modbusMapping{Type: "uint32", Scale: 1}, math.NaN(), []uint16{0, 0}},
+		{"float32 NaN", modbusMapping{Type: "float32", Scale: 1}, math.NaN(), []uint16{0x7FC0, 0x0000}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := registerValues(&test.mapping, test.value); !reflect.DeepEqual(got, test.words) {
+				t.Errorf("registerValues(%v) = %#v, want %#v", test.value, got, test.words)
+			}
+		})
+	}
+}
+
+func TestRegisterValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping modbusMapping
+		words   []uint16
+		value   float64
+	}{
+		{"int16", modbusMapping{Scale: 1}, []uint16{0xFFFE}, -2},
+		{"int16 scaled", modbusMapping{Scale: 100}, []uint16{123}, 1.23},
+		{"uint16", modbusMapping{Type: "uint16", Scale: 1}, []uint16{0xFFFE}, 65534},
+		{"int32", modbusMapping{Type: "int32", Scale: 1}, []uint16{0xFFFF, 0xFFFE}, -2},
+		{"uint32", modbusMapping{Type: "uint32", Scale: 1}, []uint16{0x0001, 0x0000}, 65536},
+		{"float32", modbusMapping{Type: "float32", Scale: 1}, []uint16{0x3FC0, 0x0000}, 1.5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := registerValue(&test.mapping, test.words); got != test.value {
+				t.Errorf("registerValue(%#v) = %v, want %v", test.words, got, test.value)
+			}
+		})
+	}
+}
+
+func testModbusServer(t *testing.T, loaded bool) (*modbusServer, chan structs.ClientCommand) {
+	config := modbusConfig{
+		HoldingRegisters: []modbusMapping{
+			{Address: 0, Module: "Engine", Variable: "throttle", Type: "int32"},
+			{Address: 2, Module: "Engine", Variable: "gear"},
+		},
+		InputRegisters: []modbusMapping{{Address: 0, Module: "Engine", Variable: "speed", Type: "float32"}},
+		Coils:          []modbusMapping{{Address: 0, Module: "Engine", Variable: "ignition"}},
+	}
+	command := make(chan structs.ClientCommand, 10)
+	server := &modbusServer{
+		command: command,
+		sim: &libcosim.Simulation{MetaData: &structs.MetaData{FMUs: []structs.FMU{{
+			Name: "Engine",
+			Variables: []structs.Variable{
+				{Name: "throttle", ValueReference: 1, Type: "Real"},
+				{Name: "gear", ValueReference: 2, Type: "Integer"},
+				{Name: "speed", ValueReference: 3, Type: "Real"},
+				{Name: "ignition", ValueReference: 4, Type: "Boolean"},
+			},
+		}}}},
+		status: &structs.SimulationStatus{Loaded: loaded},
+		read: func(pairs []string) ([]structs.Module, error) {
+			return []structs.Module{{Name: "Engine", Signals: []structs.Signal{
+				{Name: "throttle", Value: -2.0},
+				{Name: "gear", Value: 3},
+				{Name: "speed", Value: 1.5},
+				{Name: "ignition", Value: true},
+			}}}, nil
+		},
+	}
+	var err error
+	if server.holdingRegisters, err = newModbusTable(config.HoldingRegisters, true); err != nil {
+		t.Fatal(err)
+	}
+	if server.inputRegisters, err = newModbusTable(config.InputRegisters, true); err != nil {
+		t.Fatal(err)
+	}
+	if server.coils, err = newModbusTable(config.Coils, false); err != nil {
+		t.Fatal(err)
+	}
+	server.discreteInputs = modbusTable{}
+	return server, command
+}
+
+func TestModbusHandle(t *testing.T) {
+	tests := []struct {
+		name    string
+		pdu     []byte
+		reply   []byte
+		command []string
+	}{
+		{"unknown function", []byte{0x2B}, []byte{0xAB, 0x01}, nil},
+		{"read holding registers", []byte{0x03, 0, 0, 0, 3}, []byte{0x03, 6, 0xFF, 0xFF, 0xFF, 0xFE, 0, 3}, nil},
+		{"read unmapped registers", []byte{0x03, 0, 10, 0, 1}, []byte{0x03, 2, 0, 0}, nil},
+		{"read input registers", []byte{0x04, 0, 0, 0, 2}, []byte{0x04, 4, 0x3F, 0xC0, 0, 0}, nil},
+		{"read coils", []byte{0x01, 0, 0, 0, 2}, []byte{0x01, 1, 0x01}, nil},
+		{"read with wrong length", []byte{0x03, 0, 0, 0}, []byte{0x83, 0x03}, nil},
+		{"read no registers", []byte{0x03, 0, 0, 0, 0}, []byte{0x83, 0x03}, nil},
+		{"read too many registers", []byte{0x03, 0, 0, 0, 126}, []byte{0x83, 0x03}, nil},
+		{"read past the last address", []byte{0x03, 0xFF, 0xFF, 0, 2}, []byte{0x83, 0x02}, nil},
+		{"write coil", []byte{0x05, 0, 0, 0xFF, 0x00}, []byte{0x05, 0, 0, 0xFF, 0x00}, []string{"set-value", "0", "Boolean", "4", "true"}},
+		{"write coil with invalid value", []byte{0x05, 0, 0, 0x12, 0x34}, []byte{0x85, 0x03}, nil},
+		{"write unmapped coil", []byte{0x05, 0, 9, 0xFF, 0x00}, []byte{0x85, 0x02}, nil},
+		{"write multiple coils", []byte{0x0F, 0, 0, 0, 1, 1, 0x00}, []byte{0x0F, 0, 0, 0, 1}, []string{"set-value", "0", "Boolean", "4", "false"}},
+		{"write multiple coils with wrong byte count", []byte{0x0F, 0, 0, 0, 1, 2, 0x00, 0x00}, []byte{0x8F, 0x03}, nil},
+		{"write register", []byte{0x06, 0, 2, 0, 5}, []byte{0x06, 0, 2, 0, 5}, []string{"set-value", "0", "Integer", "2", "5"}},
+		{"write half of a 32 bit value", []byte{0x06, 0, 1, 0, 5}, []byte{0x86, 0x02}, nil},
+		{"write multiple registers", []byte{0x10, 0, 0, 0, 2, 4, 0xFF, 0xFF, 0xFF, 0xFE}, []byte{0x10, 0, 0, 0, 2}, []string{"set-value", "0", "Real", "1", "-2"}},
+		{"write multiple registers with wrong byte count", []byte{0x10, 0, 0, 0, 2, 3, 0, 0, 0}, []byte{0x90, 0x03}, nil},
+		{"write registers cutting a value", []byte{0x10, 0, 0, 0, 1, 2, 0, 0}, []byte{0x90, 0x02}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, command := testModbusServer(t, true)
+			if got := server.handle(test.pdu); !reflect.DeepEqual(got, test.reply) {
+				t.Errorf("handle(% X) = % X, want % X", test.pdu, got, test.reply)
+			}
+			select {
+			case cmd := <-command:
+				if !reflect.DeepEqual(cmd.Command, test.command) {
+					t.Errorf("got command %v, want %v", cmd.Command, test.command)
+				}
+			default:
+				if test.command != nil {
+					t.Errorf("got no command, want %v", test.command)
+				}
+			}
+		})
+	}
+}
+
+func TestModbusHandleNotLoaded(t *testing.T) {
+	server, _ := testModbusServer(t, false)
+	if got := server.handle([]byte{0x03, 0, 0, 0, 1}); !reflect.DeepEqual(got, []byte{0x83, 0x04}) {
+		t.Errorf("got % X without a loaded simulation, want 83 04", got)
+	}
+}
@@ -0,0 +1,204 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/libcosim"
+	"cosim-demo-app/structs"
+	"fmt"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// The MQTT bridge is configured per simulation with an mqtt.json file in the configuration folder:
+//
+//	{
+//	  "broker": "tcp://localhost:1883",
+//	  "clientId": "cosim-demo-app",
+//	  "publishInterval": 1000,
+//	  "publish": [{"module": "Engine", "variable": "speed", "topic": "plant/engine/speed"}],
+//	  "overrides": [{"module": "Engine", "variable": "throttle", "topic": "plant/engine/throttle/set",
+//	                 "resetTopic": "plant/engine/throttle/reset"}]
+//	}
+//
+// While a simulation with such a file is loaded, the values of the published variables are sent
+// as text to their topics every publishInterval milliseconds. Messages on an override topic set the
+// variable to the value in the message, and any message on a reset topic resets it. The bridge keeps
+// trying to connect while the broker can't be reached, and reconnects when the connection is lost.
+
+const (
+	mqttConfigFile              = "mqtt.json"
+	mqttDefaultPublishInterval  = 1000
+	mqttDisconnectQuiesceMillis = 250
+	mqttConnectRetryInterval    = 10 * time.Second
+)
+
+type mqttTopic struct {
+	Module     string `json:"module"`
+	Variable   string `json:"variable"`
+	Topic      string `json:"topic"`
+	ResetTopic string `json:"resetTopic,omitempty"`
+}
+
+type mqttConfig struct {
+	Broker          string      `json:"broker"`
+	ClientId        string      `json:"clientId"`
+	Username        string      `json:"username,omitempty"`
+	Password        string      `json:"password,omitempty"`
+	Qos             byte        `json:"qos"`
+	Retain          bool        `json:"retain"`
+	PublishInterval int         `json:"publishInterval"`
+	Publish         []mqttTopic `json:"publish"`
+	Overrides       []mqttTopic `json:"overrides"`
+}
+
+type mqttBridge struct {
+	config  mqttConfig
+	client  mqtt.Client
+	command chan structs.ClientCommand
+	sim     *libcosim.Simulation
+	status  *structs.SimulationStatus
+	stop    chan bool
+}
+
+// MqttBridge connects to the MQTT broker of the loaded simulation, if it has an MQTT configuration,
+// and disconnects when the simulation is torn down or another one is loaded.
+func MqttBridge(command chan structs.ClientCommand, simulationStatus *structs.SimulationStatus, sim *libcosim.Simulation) {
+	followSimulation(sim, simulationStatus, func(configDir string) func() {
+		config := mqttConfig{ClientId: "cosim-demo-app", PublishInterval: mqttDefaultPublishInterval}
+		if err := readSimulationConfig(configDir, mqttConfigFile, &config); os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			log.Println("Could not read MQTT configuration:", err)
+			return nil
+		}
+		if len(config.Broker) == 0 {
+			log.Println("Could not read MQTT configuration:", mqttConfigFile, "has no broker")
+			return nil
+		}
+		return openMqttBridge(config, command, simulationStatus, sim, mqttConnectRetryInterval).close
+	})
+}
+
+// openMqttBridge starts connecting to the broker, retrying every retryInterval until it succeeds.
+func openMqttBridge(config mqttConfig, command chan structs.ClientCommand, status *structs.SimulationStatus, sim *libcosim.Simulation, retryInterval time.Duration) *mqttBridge {
+	bridge := &mqttBridge{config: config, command: command, sim: sim, status: status, stop: make(chan bool)}
+	options := mqtt.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(config.ClientId).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(retryInterval).
+		SetOnConnectHandler(bridge.subscribe).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Println("Lost connection to MQTT broker", config.Broker+":", err)
+		})
+	bridge.client = mqtt.NewClient(options)
+	bridge.client.Connect()
+	go bridge.publishLoop()
+	return bridge
+}
+
+func (bridge *mqttBridge) close() {
+	close(bridge.stop)
+	bridge.client.Disconnect(mqttDisconnectQuiesceMillis)
+	log.Println("Disconnected from MQTT broker", bridge.config.Broker)
+}
+
+// subscribe subscribes to the override topics, also after reconnecting.
+func (bridge *mqttBridge) subscribe(client mqtt.Client) {
+	log.Println("Connected to MQTT broker", bridge.config.Broker)
+	for _, override := range bridge.config.Overrides {
+		override := override
+		bridge.subscribeTopic(client, override.Topic, func(_ mqtt.Client, message mqtt.Message) {
+			bridge.override(override, "set-value", strings.TrimSpace(string(message.Payload())))
+		})
+		if len(override.ResetTopic) > 0 {
+			bridge.subscribeTopic(client, override.ResetTopic, func(_ mqtt.Client, _ mqtt.Message) {
+				bridge.override(override, "reset-value", "")
+			})
+		}
+	}
+}
+
+func (bridge *mqttBridge) subscribeTopic(client mqtt.Client, topic string, handler mqtt.MessageHandler) {
+	token := client.Subscribe(topic, bridge.config.Qos, handler)
+	if token.Wait() && token.Error() != nil {
+		log.Println("Could not subscribe to MQTT topic", topic+":", token.Error())
+		return
+	}
+	// The broker refuses a subscription by granting the failure code 0x80 instead of a QoS.
+	for _, qos := range token.(*mqtt.SubscribeToken).Result() {
+		if qos > 2 {
+			log.Println("Could not subscribe to MQTT topic", topic+": refused by the broker")
+		}
+	}
+}
+
+func (bridge *mqttBridge) override(override mqttTopic, commandName string, value string) {
+	args, err := variableArguments(bridge.sim, bridge.status, override.Module, override.Variable)
+	if err == libcosim.ErrNotLoaded {
+		return
+	} else if err != nil {
+		log.Println("Could not apply MQTT override:", err)
+		return
+	}
+	cmd := append([]string{commandName}, args...)
+	if commandName == "set-value" {
+		cmd = append(cmd, value)
+	}
+	bridge.command <- structs.ClientCommand{Command: cmd}
+}
+
+func (bridge *mqttBridge) publishLoop() {
+	if len(bridge.config.Publish) == 0 {
+		return
+	}
+	topics := map[string]string{}
+	var pairs []string
+	for _, publish := range bridge.config.Publish {
+		topics[publish.Module+"."+publish.Variable] = publish.Topic
+		pairs = append(pairs, publish.Module, publish.Variable)
+	}
+	interval := bridge.config.PublishInterval
+	if interval <= 0 {
+		interval = mqttDefaultPublishInterval
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-bridge.stop:
+			return
+		case <-ticker.C:
+			modules, err := libcosim.VariableValues(bridge.sim, bridge.status, pairs)
+			if err == libcosim.ErrNotLoaded {
+				continue
+			} else if err != nil {
+				log.Println("Could not read values to publish:", err)
+				continue
+			}
+			bridge.publish(topics, modules)
+		}
+	}
+}
+
+// publish sends the values to their topics, unless the bridge isn't connected.
+func (bridge *mqttBridge) publish(topics map[string]string, modules []structs.Module) {
+	if !bridge.client.IsConnectionOpen() {
+		return
+	}
+	for _, module := range modules {
+		for _, signal := range module.Signals {
+			topic := topics[module.Name+"."+signal.Name]
+			bridge.client.Publish(topic, bridge.config.Qos, bridge.config.Retain, fmt.Sprint(signal.Value))
+		}
+	}
+}
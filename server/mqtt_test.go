@@ -0,0 +1,235 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/libcosim"
+	"cosim-demo-app/structs"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/rs/zerolog"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+const mqttTestTimeout = 10 * time.Second
+
+// testBroker is an embedded MQTT broker, with a hook recording the subscriptions and publications
+// and refusing subscriptions to some topics.
+type testBroker struct {
+	server  *mochi.Server
+	address string
+	hook    *testBrokerHook
+	once    sync.Once
+}
+
+type testBrokerHook struct {
+	mochi.HookBase
+	refused    map[string]bool
+	subscribed chan string
+	published  chan packets.Packet
+}
+
+func (hook *testBrokerHook) ID() string {
+	return "test"
+}
+
+func (hook *testBrokerHook) Provides(b byte) bool {
+	return b == mochi.OnConnectAuthenticate || b == mochi.OnACLCheck || b == mochi.OnSubscribed || b == mochi.OnPublished
+}
+
+func (hook *testBrokerHook) OnConnectAuthenticate(client *mochi.Client, packet packets.Packet) bool {
+	return true
+}
+
+func (hook *testBrokerHook) OnACLCheck(client *mochi.Client, topic string, write bool) bool {
+	return write || !hook.refused[topic]
+}
+
+func (hook *testBrokerHook) OnSubscribed(client *mochi.Client, packet packets.Packet, reasonCodes []byte) {
+	for _, filter := range packet.Filters {
+		hook.subscribed <- filter.Filter
+	}
+}
+
+func (hook *testBrokerHook) OnPublished(client *mochi.Client, packet packets.Packet) {
+	select {
+	case hook.published <- packet:
+	default:
+	}
+}
+
+// freeAddress returns a local address nothing listens on.
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func startTestBroker(t *testing.T, address string, refused ...string) *testBroker {
+	logger := zerolog.Nop()
+	broker := &testBroker{
+		server:  mochi.New(&mochi.Options{Logger: &logger}),
+		address: address,
+		hook: &testBrokerHook{
+			refused:    map[string]bool{},
+			subscribed: make(chan string, 100),
+			published:  make(chan packets.Packet, 100),
+		},
+	}
+	for _, topic := range refused {
+		broker.hook.refused[topic] = true
+	}
+	if err := broker.server.AddHook(broker.hook, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := broker.server.AddListener(listeners.NewTCP("tcp", address, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := broker.server.Serve(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(broker.close)
+	return broker
+}
+
+func (broker *testBroker) close() {
+	broker.once.Do(func() { broker.server.Close() })
+}
+
+// send publishes a message to the subscribers of a topic.
+func (broker *testBroker) send(t *testing.T, topic string, payload string) {
+	if err := broker.server.Publish(topic, []byte(payload), false, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (broker *testBroker) waitForSubscriptions(t *testing.T, topics ...string) {
+	want := map[string]bool{}
+	for _, topic := range topics {
+		want[topic] = true
+	}
+	for len(want) > 0 {
+		select {
+		case topic := <-broker.hook.subscribed:
+			delete(want, topic)
+		case <-time.After(mqttTestTimeout):
+			t.Fatalf("bridge did not subscribe to %v", want)
+		}
+	}
+}
+
+func mqttTestSimulation() (*libcosim.Simulation, *structs.SimulationStatus) {
+	sim := &libcosim.Simulation{MetaData: &structs.MetaData{FMUs: []structs.FMU{{
+		Name:           "Engine",
+		ExecutionIndex: 1,
+		Variables:      []structs.Variable{{Name: "throttle", ValueReference: 3, Causality: "input", Type: "Real"}},
+	}}}}
+	return sim, &structs.SimulationStatus{Loaded: true}
+}
+
+func expectCommand(t *testing.T, command chan structs.ClientCommand, want []string) {
+	select {
+	case cmd := <-command:
+		if !reflect.DeepEqual(cmd.Command, want) {
+			t.Errorf("got command %v, want %v", cmd.Command, want)
+		}
+	case <-time.After(mqttTestTimeout):
+		t.Fatalf("got no command, want %v", want)
+	}
+}
+
+func TestMqttBridgeOverrides(t *testing.T) {
+	broker := startTestBroker(t, freeAddress(t))
+	sim, status := mqttTestSimulation()
+	command := make(chan structs.ClientCommand, 10)
+	config := mqttConfig{
+		Broker:   "tcp://" + broker.address,
+		ClientId: "test",
+		Overrides: []mqttTopic{
+			{Module: "Engine", Variable: "throttle", Topic: "engine/throttle/set", ResetTopic: "engine/throttle/reset"},
+			{Module: "Engine", Variable: "missing", Topic: "engine/missing/set"},
+		},
+	}
+	bridge := openMqttBridge(config, command, status, sim, time.Second)
+	defer bridge.close()
+	broker.waitForSubscriptions(t, "engine/throttle/set", "engine/throttle/reset", "engine/missing/set")
+
+	broker.send(t, "engine/missing/set", "1")
+	broker.send(t, "engine/throttle/set", " 0.5\n")
+	expectCommand(t, command, []string{"set-value", "1", "Real", "3", "0.5"})
+	broker.send(t, "engine/throttle/reset", "")
+	expectCommand(t, command, []string{"reset-value", "1", "Real", "3"})
+}
+
+func TestMqttBridgeRetriesConnecting(t *testing.T) {
+	address := freeAddress(t)
+	sim, status := mqttTestSimulation()
+	config := mqttConfig{
+		Broker:    "tcp://" + address,
+		ClientId:  "test",
+		Overrides: []mqttTopic{{Module: "Engine", Variable: "throttle", Topic: "engine/throttle/set"}},
+	}
+	bridge := openMqttBridge(config, make(chan structs.ClientCommand, 10), status, sim, 50*time.Millisecond)
+	defer bridge.close()
+	time.Sleep(100 * time.Millisecond)
+
+	broker := startTestBroker(t, address)
+	broker.waitForSubscriptions(t, "engine/throttle/set")
+}
+
+func TestMqttBridgeResubscribes(t *testing.T) {
+	address := freeAddress(t)
+	broker := startTestBroker(t, address)
+	sim, status := mqttTestSimulation()
+	command := make(chan structs.ClientCommand, 10)
+	config := mqttConfig{
+		Broker:    "tcp://" + address,
+		ClientId:  "test",
+		Overrides: []mqttTopic{{Module: "Engine", Variable: "throttle", Topic: "engine/throttle/set"}},
+	}
+	bridge := openMqttBridge(config, command, status, sim, 50*time.Millisecond)
+	defer bridge.close()
+	broker.waitForSubscriptions(t, "engine/throttle/set")
+
+	// A restarted broker has lost the subscriptions, so the bridge must subscribe again.
+	broker.close()
+	restarted := startTestBroker(t, address)
+	restarted.waitForSubscriptions(t, "engine/throttle/set")
+	restarted.send(t, "engine/throttle/set", "0.25")
+	expectCommand(t, command, []string{"set-value", "1", "Real", "3", "0.25"})
+}
+
+func TestMqttBridgePublish(t *testing.T) {
+	broker := startTestBroker(t, freeAddress(t), "engine/throttle/set")
+	sim, status := mqttTestSimulation()
+	config := mqttConfig{
+		Broker:    "tcp://" + broker.address,
+		ClientId:  "test",
+		Overrides: []mqttTopic{{Module: "Engine", Variable: "throttle", Topic: "engine/throttle/set"}},
+	}
+	bridge := openMqttBridge(config, make(chan structs.ClientCommand, 10), status, sim, time.Second)
+	defer bridge.close()
+	// A refused subscription doesn't keep the bridge from publishing.
+	broker.waitForSubscriptions(t, "engine/throttle/set")
+
+	topics := map[string]string{"Engine.speed": "engine/speed"}
+	bridge.publish(topics, []structs.Module{{Name: "Engine", Signals: []structs.Signal{{Name: "speed", Value: 1.5}}}})
+	select {
+	case published := <-broker.hook.published:
+		if published.TopicName != "engine/speed" || string(published.Payload) != "1.5" {
+			t.Errorf("got %s on %s, want 1.5 on engine/speed", published.Payload, published.TopicName)
+		}
+	case <-time.After(mqttTestTimeout):
+		t.Fatal("bridge published nothing")
+	}
+}
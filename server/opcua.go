@@ -0,0 +1,400 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"context"
+	"cosim-demo-app/libcosim"
+	"cosim-demo-app/structs"
+	"fmt"
+	"github.com/gopcua/opcua/id"
+	opcuaserver "github.com/gopcua/opcua/server"
+	"github.com/gopcua/opcua/server/attrs"
+	"github.com/gopcua/opcua/ua"
+	"github.com/gopcua/opcua/uasc"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// The OPC UA server is configured per simulation with an opcua.json file in the configuration folder:
+//
+//	{"hosts": ["0.0.0.0", "localhost"], "port": 4840}
+//
+// The address space has a folder per simulator with its variables, named Module.variable, and a
+// Simulation object with the methods Play, Pause and LoadScenario. Inputs and parameters are
+// writable, and writes become overrides. The Write and Call services are handled here, since the
+// OPC UA server package neither passes writes on nor implements methods. The values are read with
+// VariableValues, which waits for the command loop, since the server reads them on its own goroutines.
+//
+// The server package of github.com/gopcua/opcua requires Go 1.22, which is why the application does.
+
+const (
+	opcuaConfigFile   = "opcua.json"
+	opcuaNamespace    = "urn:cosim-demo-app:simulation"
+	opcuaDefaultPort  = 4840
+	opcuaCallTimeout  = 10 * time.Second
+	opcuaControlId    = 1
+	opcuaFirstMethod  = 10
+	opcuaFirstOptions = 100
+)
+
+type opcuaConfig struct {
+	Hosts []string `json:"hosts"`
+	Port  int      `json:"port"`
+}
+
+type opcuaVariable struct {
+	module   string
+	variable structs.Variable
+}
+
+type opcuaMethod struct {
+	name      string
+	command   string
+	arguments []string
+}
+
+var opcuaMethods = []opcuaMethod{
+	{name: "Play", command: "play"},
+	{name: "Pause", command: "pause"},
+	{name: "LoadScenario", command: "load-scenario", arguments: []string{"FileName"}},
+}
+
+type opcuaServer struct {
+	command   chan structs.ClientCommand
+	clients   *libcosim.Clients
+	sim       *libcosim.Simulation
+	status    *structs.SimulationStatus
+	server    *opcuaserver.Server
+	variables map[string]opcuaVariable
+	methods   map[string]opcuaMethod
+}
+
+// OpcuaServer serves the loaded simulation over OPC UA, if it has an OPC UA configuration.
+func OpcuaServer(command chan structs.ClientCommand, clients *libcosim.Clients, simulationStatus *structs.SimulationStatus, sim *libcosim.Simulation) {
+	followSimulation(sim, simulationStatus, func(configDir string) func() {
+		config := opcuaConfig{Port: opcuaDefaultPort}
+		if err := readSimulationConfig(configDir, opcuaConfigFile, &config); os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			log.Println("Could not read OPC UA configuration:", err)
+			return nil
+		}
+		if len(config.Hosts) == 0 {
+			config.Hosts = []string{"0.0.0.0", "localhost"}
+			if hostname, err := os.Hostname(); err == nil {
+				config.Hosts = append(config.Hosts, hostname)
+			}
+		}
+		server, err := openOpcuaServer(config, command, clients, simulationStatus, sim)
+		if err != nil {
+			log.Println("Could not start OPC UA server:", err)
+			return nil
+		}
+		return server.close
+	})
+}
+
+func openOpcuaServer(config opcuaConfig, command chan structs.ClientCommand, clients *libcosim.Clients, status *structs.SimulationStatus, sim *libcosim.Simulation) (*opcuaServer, error) {
+	metaData, err := libcosim.LoadedMetaData(sim, status)
+	if err != nil {
+		return nil, err
+	}
+	options := []opcuaserver.Option{
+		opcuaserver.EnableSecurity("None", ua.MessageSecurityModeNone),
+		opcuaserver.EnableAuthMode(ua.UserTokenTypeAnonymous),
+		opcuaserver.ServerName("cosim-demo-app"),
+		opcuaserver.ProductName("cosim-demo-app"),
+	}
+	for _, host := range config.Hosts {
+		options = append(options, opcuaserver.EndPoint(host, config.Port))
+	}
+	server := &opcuaServer{
+		command:   command,
+		clients:   clients,
+		sim:       sim,
+		status:    status,
+		server:    opcuaserver.New(options...),
+		variables: map[string]opcuaVariable{},
+		methods:   map[string]opcuaMethod{},
+	}
+	server.addressSpace(metaData)
+	// Handlers registered before starting take the place of the default ones.
+	server.server.RegisterHandler(id.WriteRequest_Encoding_DefaultBinary, server.write)
+	server.server.RegisterHandler(id.CallRequest_Encoding_DefaultBinary, server.call)
+	if err := server.server.Start(context.Background()); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+func (server *opcuaServer) close() {
+	if err := server.server.Close(); err != nil {
+		log.Println("Could not stop OPC UA server:", err)
+	}
+	log.Println("Stopped OPC UA server")
+}
+
+func opcuaDataType(variableType string) uint32 {
+	switch variableType {
+	case "Real":
+		return id.Double
+	case "Integer":
+		return id.Int32
+	case "Boolean":
+		return id.Boolean
+	}
+	return id.String
+}
+
+func opcuaNode(nodeId *ua.NodeID, class ua.NodeClass, name string, attributes map[ua.AttributeID]interface{}, value opcuaserver.ValueFunc) *opcuaserver.Node {
+	values := map[ua.AttributeID]*ua.DataValue{
+		ua.AttributeIDNodeClass:   opcuaserver.DataValueFromValue(attrs.NodeClass(class)),
+		ua.AttributeIDBrowseName:  opcuaserver.DataValueFromValue(attrs.BrowseName(name)),
+		ua.AttributeIDDisplayName: opcuaserver.DataValueFromValue(attrs.DisplayName(name, "")),
+	}
+	for attribute, v := range attributes {
+		values[attribute] = opcuaserver.DataValueFromValue(v)
+	}
+	return opcuaserver.NewNode(nodeId, values, nil, value)
+}
+
+// addressSpace adds the simulators, their variables and the Simulation object with its methods.
+func (server *opcuaServer) addressSpace(metaData *structs.MetaData) {
+	namespace := opcuaserver.NewNodeNameSpace(server.server, opcuaNamespace)
+	root, _ := server.server.Namespace(0)
+	root.Objects().AddRef(namespace.Objects(), id.HasComponent, true)
+	ns := namespace.ID()
+
+	for _, fmu := range metaData.FMUs {
+		folder := namespace.AddNode(opcuaserver.NewFolderNode(ua.NewStringNodeID(ns, fmu.Name), fmu.Name))
+		namespace.Objects().AddRef(folder, id.Organizes, true)
+		for _, variable := range fmu.Variables {
+			nodeId := ua.NewStringNodeID(ns, fmu.Name+"."+variable.Name)
+			access := byte(ua.AccessLevelTypeCurrentRead)
+			if variable.Causality == "input" || variable.Causality == "parameter" {
+				access |= byte(ua.AccessLevelTypeCurrentWrite)
+			}
+			v := opcuaVariable{module: fmu.Name, variable: variable}
+			node := namespace.AddNode(opcuaNode(nodeId, ua.NodeClassVariable, variable.Name, map[ua.AttributeID]interface{}{
+				ua.AttributeIDDataType:        attrs.DataType(ua.NewNumericNodeID(0, opcuaDataType(variable.Type))),
+				ua.AttributeIDValueRank:       int32(-1),
+				ua.AttributeIDAccessLevel:     access,
+				ua.AttributeIDUserAccessLevel: access,
+			}, func() *ua.DataValue {
+				return server.read(v)
+			}))
+			folder.AddRef(node, id.HasComponent, true)
+			server.variables[nodeId.String()] = v
+		}
+	}
+
+	control := namespace.AddNode(opcuaNode(ua.NewNumericNodeID(ns, opcuaControlId), ua.NodeClassObject, "Simulation", nil, nil))
+	namespace.Objects().AddRef(control, id.HasComponent, true)
+	for i, method := range opcuaMethods {
+		methodId := ua.NewNumericNodeID(ns, uint32(opcuaFirstMethod+i))
+		node := namespace.AddNode(opcuaNode(methodId, ua.NodeClassMethod, method.name, map[ua.AttributeID]interface{}{
+			ua.AttributeIDExecutable:     true,
+			ua.AttributeIDUserExecutable: true,
+		}, nil))
+		control.AddRef(node, id.HasComponent, true)
+		server.methods[methodId.String()] = method
+
+		var inputArguments []*ua.ExtensionObject
+		for _, argument := range method.arguments {
+			inputArguments = append(inputArguments, ua.NewExtensionObject(&ua.Argument{
+				Name:      argument,
+				DataType:  ua.NewNumericNodeID(0, id.String),
+				ValueRank: -1,
+			}))
+		}
+		outputArguments := []*ua.ExtensionObject{ua.NewExtensionObject(&ua.Argument{
+			Name:      "Message",
+			DataType:  ua.NewNumericNodeID(0, id.String),
+			ValueRank: -1,
+		})}
+		properties := map[string][]*ua.ExtensionObject{"OutputArguments": outputArguments}
+		if len(inputArguments) > 0 {
+			properties["InputArguments"] = inputArguments
+		}
+		for j, name := range []string{"InputArguments", "OutputArguments"} {
+			arguments, exists := properties[name]
+			if !exists {
+				continue
+			}
+			property := namespace.AddNode(opcuaNode(ua.NewNumericNodeID(ns, uint32(opcuaFirstOptions+2*i+j)), ua.NodeClassVariable, name, map[ua.AttributeID]interface{}{
+				ua.AttributeIDDataType:    attrs.DataType(ua.NewNumericNodeID(0, id.Argument)),
+				ua.AttributeIDValueRank:   int32(1),
+				ua.AttributeIDAccessLevel: byte(ua.AccessLevelTypeCurrentRead),
+			}, func() *ua.DataValue {
+				return opcuaserver.DataValueFromValue(arguments)
+			}))
+			node.AddRef(property, id.HasProperty, true)
+		}
+	}
+}
+
+// read returns the value of a variable from the last value observer.
+func (server *opcuaServer) read(v opcuaVariable) *ua.DataValue {
+	bad := &ua.DataValue{
+		EncodingMask:    ua.DataValueStatusCode | ua.DataValueServerTimestamp,
+		Status:          ua.StatusBadNotReadable,
+		ServerTimestamp: time.Now(),
+	}
+	modules, err := libcosim.VariableValues(server.sim, server.status, []string{v.module, v.variable.Name})
+	if err != nil || len(modules) == 0 || len(modules[0].Signals) == 0 {
+		return bad
+	}
+	value := modules[0].Signals[0].Value
+	var converted interface{}
+	switch v.variable.Type {
+	case "Real":
+		number, _ := numericValue(value)
+		converted = number
+	case "Integer":
+		number, _ := numericValue(value)
+		converted = int32(number)
+	case "Boolean":
+		number, _ := numericValue(value)
+		converted = number != 0
+	default:
+		converted = fmt.Sprint(value)
+	}
+	return &ua.DataValue{
+		EncodingMask:    ua.DataValueValue | ua.DataValueSourceTimestamp | ua.DataValueServerTimestamp,
+		Value:           ua.MustVariant(converted),
+		SourceTimestamp: time.Now(),
+		ServerTimestamp: time.Now(),
+	}
+}
+
+func opcuaResponseHeader(request *ua.RequestHeader) *ua.ResponseHeader {
+	return &ua.ResponseHeader{
+		Timestamp:          time.Now(),
+		RequestHandle:      request.RequestHandle,
+		ServiceResult:      ua.StatusOK,
+		ServiceDiagnostics: &ua.DiagnosticInfo{},
+		StringTable:        []string{},
+		AdditionalHeader:   ua.NewExtensionObject(nil),
+	}
+}
+
+// run executes a command and returns the status code for its outcome, and its feedback message.
+func (server *opcuaServer) run(cmd ...string) (ua.StatusCode, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), opcuaCallTimeout)
+	defer cancel()
+	response, err := runCommand(ctx, server.command, server.clients, cmd...)
+	if err != nil {
+		return ua.StatusBadTimeout, err.Error()
+	}
+	if !response.Feedback.Success {
+		log.Println("OPC UA request failed:", response.Feedback.Message)
+		return ua.StatusBadInvalidState, response.Feedback.Message
+	}
+	return ua.StatusOK, response.Feedback.Message
+}
+
+// writeValue formats a written value as the set-value command expects it for the type of the variable.
+func writeValue(variableType string, value interface{}) (string, bool) {
+	switch variableType {
+	case "Real", "Integer":
+		number, ok := numericValue(value)
+		if !ok {
+			return "", false
+		}
+		if variableType == "Integer" {
+			return strconv.Itoa(int(number)), true
+		}
+		return strconv.FormatFloat(number, 'g', -1, 64), true
+	case "Boolean":
+		b, ok := value.(bool)
+		return strconv.FormatBool(b), ok
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+func (server *opcuaServer) write(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	request, ok := r.(*ua.WriteRequest)
+	if !ok {
+		return nil, ua.StatusBadRequestTypeInvalid
+	}
+	results := make([]ua.StatusCode, len(request.NodesToWrite))
+	for i, node := range request.NodesToWrite {
+		v, exists := server.variables[node.NodeID.String()]
+		switch {
+		case !exists || node.AttributeID != ua.AttributeIDValue:
+			results[i] = ua.StatusBadNotWritable
+		case v.variable.Causality != "input" && v.variable.Causality != "parameter":
+			results[i] = ua.StatusBadNotWritable
+		case node.Value == nil || node.Value.Value == nil:
+			results[i] = ua.StatusBadTypeMismatch
+		default:
+			value, ok := writeValue(v.variable.Type, node.Value.Value.Value())
+			if !ok {
+				results[i] = ua.StatusBadTypeMismatch
+				continue
+			}
+			args, err := variableArguments(server.sim, server.status, v.module, v.variable.Name)
+			if err != nil {
+				results[i] = ua.StatusBadNodeIDUnknown
+				continue
+			}
+			results[i], _ = server.run(append([]string{"set-value"}, append(args, value)...)...)
+		}
+	}
+	return &ua.WriteResponse{
+		ResponseHeader:  opcuaResponseHeader(request.RequestHeader),
+		Results:         results,
+		DiagnosticInfos: []*ua.DiagnosticInfo{},
+	}, nil
+}
+
+func (server *opcuaServer) call(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	request, ok := r.(*ua.CallRequest)
+	if !ok {
+		return nil, ua.StatusBadRequestTypeInvalid
+	}
+	results := make([]*ua.CallMethodResult, len(request.MethodsToCall))
+	for i, call := range request.MethodsToCall {
+		method, exists := server.methods[call.MethodID.String()]
+		if !exists {
+			results[i] = &ua.CallMethodResult{StatusCode: ua.StatusBadMethodInvalid}
+			continue
+		}
+		if len(call.InputArguments) != len(method.arguments) {
+			results[i] = &ua.CallMethodResult{StatusCode: ua.StatusBadArgumentsMissing}
+			continue
+		}
+		cmd := []string{method.command}
+		argumentResults := make([]ua.StatusCode, len(call.InputArguments))
+		for j, argument := range call.InputArguments {
+			s, ok := argument.Value().(string)
+			if !ok {
+				argumentResults[j] = ua.StatusBadTypeMismatch
+			}
+			cmd = append(cmd, s)
+		}
+		result := &ua.CallMethodResult{InputArgumentResults: argumentResults}
+		for _, argumentResult := range argumentResults {
+			if argumentResult != ua.StatusOK {
+				result.StatusCode = ua.StatusBadInvalidArgument
+			}
+		}
+		if result.StatusCode == ua.StatusOK {
+			var message string
+			result.StatusCode, message = server.run(cmd...)
+			result.OutputArguments = []*ua.Variant{ua.MustVariant(message)}
+		}
+		results[i] = result
+	}
+	return &ua.CallResponse{
+		ResponseHeader:  opcuaResponseHeader(request.RequestHeader),
+		Results:         results,
+		DiagnosticInfos: []*ua.DiagnosticInfo{},
+	}, nil
+}
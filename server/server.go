@@ -5,23 +5,29 @@
 package server
 
 import (
+	"context"
 	"cosim-demo-app/libcosim"
 	"cosim-demo-app/structs"
 	"encoding/json"
+	"errors"
 	"github.com/gobuffalo/packr"
 	"github.com/gorilla/mux"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func Server(command chan []string, state chan structs.JsonResponse, simulationStatus *structs.SimulationStatus, sim *libcosim.Simulation) {
+func Server(command chan structs.ClientCommand, clients *libcosim.Clients, simulationStatus *structs.SimulationStatus, sim *libcosim.Simulation, metrics *libcosim.Metrics, webhooksFile string) {
 	router := mux.NewRouter()
 	box := packr.NewBox("../resources/public")
 
 	router.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(libcosim.GenerateJsonResponse(simulationStatus, sim, structs.CommandFeedback{}, structs.ShortLivedData{}))
+		json.NewEncoder(w).Encode(libcosim.GenerateJsonResponse(simulationStatus, sim, simulationStatus.View, structs.CommandFeedback{}, structs.ShortLivedData{}))
 	})
 
 	router.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
@@ -42,29 +48,93 @@ func Server(command chan []string, state chan structs.JsonResponse, simulationSt
 
 	router.HandleFunc("/plot-config", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		configDir := simulationStatus.ConfigDir
-		trends := simulationStatus.Trends
-		plots := []structs.Plot{}
-		for i := 0; i < len(trends); i++ {
-			trendValues := trends[i].TrendSignals
-			variables := []structs.PlotVariable{}
-			for j := 0; j < len(trendValues); j++ {
-				plotVariable := structs.PlotVariable{trendValues[j].Module, trendValues[j].Signal}
-				variables = append(variables, plotVariable)
+		_, msg := libcosim.SavePlotConfig(sim, simulationStatus, "")
+		log.Println(msg)
+		json.NewEncoder(w).Encode(msg)
+	}).Methods("POST")
+
+	router.HandleFunc("/plot-configs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(libcosim.ListPlotConfigs(sim, simulationStatus))
+	}).Methods("GET")
+
+	router.HandleFunc("/plot-configs/{name}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	}).Methods("OPTIONS")
+
+	router.HandleFunc("/plot-configs/{name}", func(w http.ResponseWriter, r *http.Request) {
+		success, msg := libcosim.SavePlotConfig(sim, simulationStatus, mux.Vars(r)["name"])
+		writePlotConfigFeedback(w, success, msg)
+	}).Methods("POST")
+
+	router.HandleFunc("/plot-configs/{name}", func(w http.ResponseWriter, r *http.Request) {
+		success, msg := libcosim.DeletePlotConfig(sim, simulationStatus, mux.Vars(r)["name"])
+		writePlotConfigFeedback(w, success, msg)
+	}).Methods("DELETE")
+
+	router.HandleFunc("/plot-configs/{name}/default", func(w http.ResponseWriter, r *http.Request) {
+		success, msg := libcosim.SetDefaultPlotConfig(sim, simulationStatus, mux.Vars(r)["name"])
+		writePlotConfigFeedback(w, success, msg)
+	}).Methods("PUT")
+
+	router.HandleFunc("/trends/{id}/export", func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=trend-"+id+".csv")
+		err := libcosim.ExportTrend(sim, simulationStatus, id, w)
+		if err != nil {
+			log.Println("Could not export trend:", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+	}).Methods("GET")
+
+	router.HandleFunc("/captures/{ids}/export", func(w http.ResponseWriter, r *http.Request) {
+		ids := mux.Vars(r)["ids"]
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=capture-"+strings.ReplaceAll(ids, ",", "-")+".csv")
+		err := libcosim.ExportCaptures(sim, simulationStatus, strings.Split(ids, ","), w)
+		if err != nil {
+			log.Println("Could not export captures:", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+	}).Methods("GET")
+
+	router.HandleFunc("/bookmarks/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=bookmarks.csv")
+		err := libcosim.ExportBookmarks(sim, simulationStatus, w)
+		if err != nil {
+			log.Println("Could not export bookmarks:", err)
+		}
+	}).Methods("GET")
+
+	router.HandleFunc("/runs", func(w http.ResponseWriter, r *http.Request) {
+		root := libcosim.RunsRoot(sim, simulationStatus)
+		if len(root) == 0 {
+			http.Error(w, "No log folder is known, load a simulation with a log folder first", http.StatusBadRequest)
+			return
+		}
+		if folder := r.URL.Query().Get("root"); len(folder) > 0 {
+			if !filepath.IsAbs(folder) {
+				folder = filepath.Join(root, folder)
+			}
+			if !libcosim.WithinFolder(root, folder) {
+				http.Error(w, "Runs can only be listed within the log folder "+root, http.StatusForbidden)
+				return
 			}
-			plot := structs.Plot{trends[i].Label, trends[i].PlotType, variables}
-			plots = append(plots, plot)
+			root = folder
 		}
-		plotConfig := structs.PlotConfig{plots}
-		plotConfigJson, _ := json.Marshal(plotConfig)
-		err := ioutil.WriteFile(configDir+"/"+"PlotConfig.json", plotConfigJson, 0644)
+		runs, err := libcosim.ListRuns(root)
 		if err != nil {
-			log.Println("Could not write PlotConfig to file, data: ", plotConfig, ", error was:", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
 		}
-		msg := "Wrote plot configuration to " + configDir + "/" + "PlotConfig.json"
-		log.Println(msg)
-		json.NewEncoder(w).Encode(msg)
-	}).Methods("POST")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(runs)
+	}).Methods("GET")
 
 	router.HandleFunc("/value/{module}/{cardinality}/{signal}", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -79,13 +149,121 @@ func Server(command chan []string, state chan structs.JsonResponse, simulationSt
 		body, _ := ioutil.ReadAll(r.Body)
 		commandRequest := []string{}
 		json.Unmarshal(body, &commandRequest)
-		command <- commandRequest
+		if len(commandRequest) > 0 {
+			command <- structs.ClientCommand{Command: commandRequest}
+		}
 	}).Methods("PUT")
 
-	router.HandleFunc("/ws", WebsocketHandler(command, state))
+	router.HandleFunc("/ws", WebsocketHandler(command, clients))
+
+	router.HandleFunc("/events", EventsHandler(command, clients)).Methods("GET")
+
+	router.HandleFunc("/metrics", MetricsHandler(metrics)).Methods("GET")
+
+	router.HandleFunc("/healthz", HealthHandler(metrics)).Methods("GET")
+
+	router.HandleFunc("/readyz", ReadyHandler(metrics)).Methods("GET")
+
+	hooks, err := newWebhooks(webhooksFile)
+	if err != nil {
+		log.Fatal("Could not read webhook configuration: ", err)
+	}
+	go hooks.watch(metrics)
+	router.HandleFunc("/webhooks", hooks.listHandler).Methods("GET")
+	router.HandleFunc("/webhooks", hooks.addHandler).Methods("POST")
+	router.HandleFunc("/webhooks/{id}", hooks.removeHandler).Methods("DELETE")
 
 	//Default handler
 	router.PathPrefix("/").Handler(http.FileServer(box))
 
 	log.Fatal(http.ListenAndServe(":8000", router))
 }
+
+func writePlotConfigFeedback(w http.ResponseWriter, success bool, msg string) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	log.Println(msg)
+	if !success {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(msg)
+}
+
+// variableArguments returns the slave index, type and value reference of a variable, as the
+// set-value and reset-value commands expect them.
+func variableArguments(sim *libcosim.Simulation, status *structs.SimulationStatus, module string, variable string) ([]string, error) {
+	metaData, err := libcosim.LoadedMetaData(sim, status)
+	if err != nil {
+		return nil, err
+	}
+	for _, fmu := range metaData.FMUs {
+		if fmu.Name != module {
+			continue
+		}
+		for _, v := range fmu.Variables {
+			if v.Name == variable {
+				return []string{strconv.Itoa(fmu.ExecutionIndex), v.Type, strconv.Itoa(v.ValueReference)}, nil
+			}
+		}
+	}
+	return nil, errors.New("Variable " + module + "." + variable + " does not exist")
+}
+
+const simulationCheckInterval = time.Second
+
+// followSimulation calls start with the configuration folder of every simulation that is loaded or reset,
+// and the function start returns, if any, when the simulation is torn down, reset or another one is loaded.
+func followSimulation(sim *libcosim.Simulation, status *structs.SimulationStatus, start func(configDir string) (stop func())) {
+	var stop func()
+	var configDir string
+	var generation int
+	for range time.Tick(simulationCheckInterval) {
+		current, currentGeneration := libcosim.LoadedConfigDir(sim, status)
+		if current == configDir && currentGeneration == generation {
+			continue
+		}
+		configDir = current
+		generation = currentGeneration
+		if stop != nil {
+			stop()
+			stop = nil
+		}
+		if len(configDir) > 0 {
+			stop = start(configDir)
+		}
+	}
+}
+
+// readSimulationConfig reads a JSON configuration file in the configuration folder of a simulation.
+func readSimulationConfig(configDir string, name string, config interface{}) error {
+	data, err := ioutil.ReadFile(filepath.Join(configDir, name))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return errors.New(name + ": " + err.Error())
+	}
+	return nil
+}
+
+// runCommand executes a command as a client of its own, and returns the response to it, which
+// holds the command feedback.
+func runCommand(ctx context.Context, command chan structs.ClientCommand, clients *libcosim.Clients, cmd ...string) (structs.JsonResponse, error) {
+	clientId, state := clients.Register()
+	defer clients.Unregister(clientId)
+	select {
+	case command <- structs.ClientCommand{ClientId: clientId, Command: cmd}:
+	case <-ctx.Done():
+		return structs.JsonResponse{}, ctx.Err()
+	}
+	for {
+		select {
+		case response := <-state:
+			if response.Feedback != nil {
+				return response, nil
+			}
+		case <-ctx.Done():
+			return structs.JsonResponse{}, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,368 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"cosim-demo-app/libcosim"
+	"cosim-demo-app/structs"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"log"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// UDP streaming is configured per simulation with a udp.json file in the configuration folder:
+//
+//	{
+//	  "outputs": [{"destinations": ["127.0.0.1:9000", "239.1.1.1:9001"], "format": "binary", "every": 1,
+//	               "signals": [{"module": "Vessel", "variable": "roll"}, {"module": "Vessel", "variable": "pitch"}]}],
+//	  "input": {"address": ":9100", "format": "json", "overrides": [{"module": "Vessel", "variable": "rudder"}]}
+//	}
+//
+// Every output sends its signals to all of its destinations, unicast or multicast, every steps of
+// the simulation. The values of the steps come from a step stream, see libcosim.StreamSteps. A binary datagram holds, big endian, the step number
+// as uint64, the simulation time in seconds as float64, the number of values as uint16, and the values
+// as float64 in the order of the signals. Booleans are sent as 0 and 1, and strings as NaN. A JSON
+// datagram is an object like {"step": 120, "time": 12.0, "values": {"Vessel.roll": 0.1}}.
+//
+// The input listens for datagrams of the same format, on a multicast group if the address is one, and
+// overrides the variables listed in overrides with the values in them. A binary datagram has the values
+// in the order of the overrides, and a NaN resets the override. In a JSON datagram a null resets it.
+// Values are only applied when they change.
+//
+// The step number is the simulation time divided by the step size, which is stepSize in udp.json if
+// given, else the BaseStepSize in OspSystemStructure.xml, else the step size used for FMU folders.
+
+const (
+	udpConfigFile       = "udp.json"
+	udpDefaultStepSize  = 0.1
+	udpMaxDatagram      = 65507
+	udpBinaryHeaderSize = 18
+)
+
+type udpSignal struct {
+	Module   string `json:"module"`
+	Variable string `json:"variable"`
+}
+
+type udpOutputConfig struct {
+	Destinations []string    `json:"destinations"`
+	Format       string      `json:"format"`
+	Every        int         `json:"every"`
+	Signals      []udpSignal `json:"signals"`
+}
+
+type udpInputConfig struct {
+	Address   string      `json:"address"`
+	Format    string      `json:"format"`
+	Overrides []udpSignal `json:"overrides"`
+}
+
+type udpConfig struct {
+	StepSize float64           `json:"stepSize,omitempty"`
+	Outputs  []udpOutputConfig `json:"outputs"`
+	Input    *udpInputConfig   `json:"input,omitempty"`
+}
+
+type udpDatagram struct {
+	Step   int64                  `json:"step"`
+	Time   float64                `json:"time"`
+	Values map[string]interface{} `json:"values"`
+}
+
+type udpOutput struct {
+	config      udpOutputConfig
+	connections []*net.UDPConn
+	names       []string
+	steps       *libcosim.StepStream
+	lastStep    int64
+}
+
+type udpStream struct {
+	command   chan structs.ClientCommand
+	sim       *libcosim.Simulation
+	status    *structs.SimulationStatus
+	stepSize  float64
+	outputs   []*udpOutput
+	input     *net.UDPConn
+	format    string
+	overrides []string
+	arguments map[string][]string
+}
+
+// UdpStream streams signals of the loaded simulation over UDP, and applies overrides received over
+// UDP, if the simulation has a UDP configuration.
+func UdpStream(command chan structs.ClientCommand, simulationStatus *structs.SimulationStatus, sim *libcosim.Simulation) {
+	followSimulation(sim, simulationStatus, func(configDir string) func() {
+		var config udpConfig
+		if err := readSimulationConfig(configDir, udpConfigFile, &config); os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			log.Println("Could not read UDP configuration:", err)
+			return nil
+		}
+		if config.StepSize <= 0 {
+			config.StepSize = ospBaseStepSize(configDir)
+		}
+		stream, err := openUdpStream(config, command, simulationStatus, sim)
+		if err != nil {
+			log.Println("Could not start UDP streaming:", err)
+			return nil
+		}
+		return stream.close
+	})
+}
+
+// ospBaseStepSize returns the base step size of an OSP system structure, or the default step size.
+func ospBaseStepSize(configDir string) float64 {
+	var structure struct {
+		BaseStepSize float64 `xml:"BaseStepSize"`
+	}
+	data, err := ioutil.ReadFile(filepath.Join(configDir, "OspSystemStructure.xml"))
+	if err != nil || xml.Unmarshal(data, &structure) != nil || structure.BaseStepSize <= 0 {
+		return udpDefaultStepSize
+	}
+	return structure.BaseStepSize
+}
+
+func checkUdpFormat(format string) error {
+	if format != "binary" && format != "json" {
+		return errors.New("Unknown UDP format " + format)
+	}
+	return nil
+}
+
+func openUdpStream(config udpConfig, command chan structs.ClientCommand, status *structs.SimulationStatus, sim *libcosim.Simulation) (*udpStream, error) {
+	stream := &udpStream{command: command, sim: sim, status: status, stepSize: config.StepSize, arguments: map[string][]string{}}
+	for _, outputConfig := range config.Outputs {
+		output, err := openUdpOutput(outputConfig, status, sim)
+		if err != nil {
+			stream.closeConnections()
+			return nil, err
+		}
+		stream.outputs = append(stream.outputs, output)
+	}
+	if config.Input != nil {
+		if err := stream.openInput(*config.Input); err != nil {
+			stream.closeConnections()
+			return nil, err
+		}
+		log.Println("Receiving UDP overrides on", config.Input.Address)
+		go stream.receiveLoop()
+	}
+	for _, output := range stream.outputs {
+		go stream.sendLoop(output)
+	}
+	return stream, nil
+}
+
+func openUdpOutput(config udpOutputConfig, status *structs.SimulationStatus, sim *libcosim.Simulation) (*udpOutput, error) {
+	if len(config.Format) == 0 {
+		config.Format = "binary"
+	}
+	if err := checkUdpFormat(config.Format); err != nil {
+		return nil, err
+	}
+	if config.Every <= 0 {
+		config.Every = 1
+	}
+	output := &udpOutput{config: config, lastStep: -1}
+	var pairs []string
+	for _, signal := range config.Signals {
+		pairs = append(pairs, signal.Module, signal.Variable)
+		output.names = append(output.names, signal.Module+"."+signal.Variable)
+	}
+	for _, destination := range config.Destinations {
+		address, err := net.ResolveUDPAddr("udp", destination)
+		if err == nil {
+			var connection *net.UDPConn
+			if connection, err = net.DialUDP("udp", nil, address); err == nil {
+				output.connections = append(output.connections, connection)
+				continue
+			}
+		}
+		output.close(sim)
+		return nil, err
+	}
+	steps, err := libcosim.StreamSteps(sim, status, pairs)
+	if err != nil {
+		output.close(sim)
+		return nil, err
+	}
+	output.steps = steps
+	log.Println("Streaming", len(output.names), "signals over UDP to", config.Destinations)
+	return output, nil
+}
+
+func (output *udpOutput) close(sim *libcosim.Simulation) {
+	if output.steps != nil {
+		libcosim.StopStepStream(sim, output.steps)
+	}
+	for _, connection := range output.connections {
+		connection.Close()
+	}
+}
+
+func (stream *udpStream) openInput(config udpInputConfig) error {
+	stream.format = config.Format
+	if len(stream.format) == 0 {
+		stream.format = "binary"
+	}
+	if err := checkUdpFormat(stream.format); err != nil {
+		return err
+	}
+	for _, override := range config.Overrides {
+		args, err := variableArguments(stream.sim, stream.status, override.Module, override.Variable)
+		if err != nil {
+			return err
+		}
+		name := override.Module + "." + override.Variable
+		stream.overrides = append(stream.overrides, name)
+		stream.arguments[name] = args
+	}
+	address, err := net.ResolveUDPAddr("udp", config.Address)
+	if err != nil {
+		return err
+	}
+	if address.IP != nil && address.IP.IsMulticast() {
+		stream.input, err = net.ListenMulticastUDP("udp", nil, address)
+	} else {
+		stream.input, err = net.ListenUDP("udp", address)
+	}
+	return err
+}
+
+func (stream *udpStream) closeConnections() {
+	for _, output := range stream.outputs {
+		output.close(stream.sim)
+	}
+	if stream.input != nil {
+		stream.input.Close()
+	}
+}
+
+func (stream *udpStream) close() {
+	stream.closeConnections()
+	log.Println("Stopped UDP streaming")
+}
+
+// sendLoop sends the signals of an output at the steps it is due, until its step stream is closed.
+func (stream *udpStream) sendLoop(output *udpOutput) {
+	for sample := range output.steps.Samples {
+		step := int64(math.Round(sample.Time / stream.stepSize))
+		// The step number goes back when the simulation is reset.
+		if output.lastStep >= 0 && step >= output.lastStep && step < output.lastStep+int64(output.config.Every) {
+			continue
+		}
+		output.lastStep = step
+		stream.send(output, step, sample.Time, sample.Values)
+	}
+}
+
+func (stream *udpStream) send(output *udpOutput, step int64, simulationTime float64, values map[string]interface{}) {
+	var err error
+	var datagram []byte
+	if output.config.Format == "json" {
+		if datagram, err = json.Marshal(udpDatagram{Step: step, Time: simulationTime, Values: values}); err != nil {
+			log.Println("Could not encode UDP datagram:", err)
+			return
+		}
+	} else {
+		datagram = make([]byte, udpBinaryHeaderSize, udpBinaryHeaderSize+8*len(output.names))
+		binary.BigEndian.PutUint64(datagram, uint64(step))
+		binary.BigEndian.PutUint64(datagram[8:], math.Float64bits(simulationTime))
+		binary.BigEndian.PutUint16(datagram[16:], uint16(len(output.names)))
+		for _, name := range output.names {
+			value, ok := numericValue(values[name])
+			if !ok {
+				value = math.NaN()
+			}
+			datagram = binary.BigEndian.AppendUint64(datagram, math.Float64bits(value))
+		}
+	}
+	for _, connection := range output.connections {
+		// Errors are ignored, a destination that is not listening must not stop the others.
+		connection.Write(datagram)
+	}
+}
+
+// decode returns the values in a datagram by variable name, with nil for the ones to reset.
+func (stream *udpStream) decode(datagram []byte) (map[string]interface{}, error) {
+	if stream.format == "json" {
+		var decoded udpDatagram
+		if err := json.Unmarshal(datagram, &decoded); err != nil {
+			return nil, err
+		}
+		return decoded.Values, nil
+	}
+	if len(datagram) < udpBinaryHeaderSize {
+		return nil, errors.New("Datagram of " + strconv.Itoa(len(datagram)) + " bytes is too short")
+	}
+	count := int(binary.BigEndian.Uint16(datagram[16:]))
+	if len(datagram) < udpBinaryHeaderSize+8*count {
+		return nil, errors.New("Datagram of " + strconv.Itoa(len(datagram)) + " bytes is too short for " + strconv.Itoa(count) + " values")
+	}
+	values := map[string]interface{}{}
+	for i := 0; i < count && i < len(stream.overrides); i++ {
+		value := math.Float64frombits(binary.BigEndian.Uint64(datagram[udpBinaryHeaderSize+8*i:]))
+		if math.IsNaN(value) {
+			values[stream.overrides[i]] = nil
+		} else {
+			values[stream.overrides[i]] = value
+		}
+	}
+	return values, nil
+}
+
+func (stream *udpStream) receiveLoop() {
+	buffer := make([]byte, udpMaxDatagram)
+	applied := map[string]string{}
+	for {
+		n, _, err := stream.input.ReadFromUDP(buffer)
+		if err != nil {
+			// The connection is closed when the simulation is torn down.
+			return
+		}
+		values, err := stream.decode(buffer[:n])
+		if err != nil {
+			log.Println("Could not decode UDP datagram:", err)
+			continue
+		}
+		if _, err := libcosim.LoadedMetaData(stream.sim, stream.status); err != nil {
+			continue
+		}
+		for name, value := range values {
+			args, exists := stream.arguments[name]
+			if !exists {
+				continue
+			}
+			cmd := append([]string{"reset-value"}, args...)
+			text := ""
+			if value != nil {
+				if number, ok := value.(float64); ok && args[1] == "Boolean" {
+					value = number != 0
+				}
+				var ok bool
+				if text, ok = writeValue(args[1], value); !ok {
+					log.Println("Could not apply UDP override of", name, "with", value)
+					continue
+				}
+				cmd = append([]string{"set-value"}, append(args, text)...)
+			}
+			if last, exists := applied[name]; exists && last == cmd[0]+text {
+				continue
+			}
+			applied[name] = cmd[0] + text
+			stream.command <- structs.ClientCommand{Command: cmd}
+		}
+	}
+}
@@ -0,0 +1,470 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"bytes"
+	"cosim-demo-app/libcosim"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhooks are posted a JSON payload on simulation events. They are registered in the file given
+// by the -webhooks flag, through the API, POST /webhooks with {"url": "http://host/hook", "events":
+// ["loaded"]}, or per simulation with a webhooks.json file in the configuration folder:
+//
+//	{"webhooks": [{"url": "http://host/hook", "events": ["execution-error", "scenario-finished"]}]}
+//
+// The file of the -webhooks flag also lists the URL prefixes the API accepts, and webhooks added or
+// removed through the API are saved to it. Without it, webhooks can't be added through the API:
+//
+//	{"allow": ["http://host/"], "webhooks": [{"url": "http://host/hook"}]}
+//
+// The ones in webhooks.json apply while that simulation is loaded. Without events a webhook gets
+// all of them:
+//
+//	loaded             a simulation was loaded
+//	unloaded           the simulation was torn down
+//	execution-error    the execution failed
+//	scenario-finished  the running scenario came to its end
+//	scenario-aborted   the running scenario was aborted
+//	trigger-fired      the trigger of a trend fired
+//	bookmark           a bookmark was added, by a user or automatically
+//
+// The payload holds the event, the time it was noticed, data about the event and the status of the
+// simulation, as cached by the command loop. Deliveries that fail or get an answer other than 2xx,
+// redirects included, are retried with growing delays.
+
+const (
+	webhooksConfigFile    = "webhooks.json"
+	webhookCheckInterval  = 250 * time.Millisecond
+	webhookTimeout        = 10 * time.Second
+	webhookAttempts       = 4
+	webhookFirstRetryWait = time.Second
+)
+
+var webhookEvents = map[string]bool{
+	"loaded":            true,
+	"unloaded":          true,
+	"execution-error":   true,
+	"scenario-finished": true,
+	"scenario-aborted":  true,
+	"trigger-fired":     true,
+	"bookmark":          true,
+}
+
+type webhook struct {
+	Id     int      `json:"id,omitempty"`
+	Url    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Source string   `json:"source,omitempty"`
+}
+
+type webhooksConfig struct {
+	Allow    []string  `json:"allow,omitempty"`
+	Webhooks []webhook `json:"webhooks"`
+}
+
+type webhookStatus struct {
+	Loaded                       bool    `json:"loaded"`
+	ConfigDir                    string  `json:"configDir,omitempty"`
+	Status                       string  `json:"status"`
+	ExecutionState               string  `json:"executionState,omitempty"`
+	SimulationTime               float64 `json:"simulationTime"`
+	RollingAverageRealTimeFactor float64 `json:"rollingAverageRealTimeFactor"`
+	RealTimeFactorTarget         float64 `json:"realTimeFactorTarget"`
+	RunningScenario              string  `json:"runningScenario,omitempty"`
+	LastErrorCode                string  `json:"lastErrorCode,omitempty"`
+	LastErrorMessage             string  `json:"lastErrorMessage,omitempty"`
+}
+
+type webhookPayload struct {
+	Event     string        `json:"event"`
+	Timestamp time.Time     `json:"timestamp"`
+	Data      interface{}   `json:"data,omitempty"`
+	Status    webhookStatus `json:"status"`
+}
+
+type webhooks struct {
+	mutex  sync.Mutex
+	nextId int
+	hooks  []webhook
+	client *http.Client
+	file   string
+	allow  []string
+}
+
+// simulationSnapshot is what the watcher compares between checks to notice events.
+type simulationSnapshot struct {
+	loaded          bool
+	configDir       string
+	generation      int
+	state           string
+	scenario        string
+	abortedScenario bool
+	bookmarks       map[int]bool
+	firedTriggers   map[int]bool
+}
+
+// newWebhooks reads the allowed URL prefixes and the webhooks of the API from a file, if given.
+// The file is created when the first webhook is added through the API.
+func newWebhooks(file string) (*webhooks, error) {
+	hooks := &webhooks{
+		client: &http.Client{
+			Timeout: webhookTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		file: file,
+	}
+	if len(file) == 0 {
+		return hooks, nil
+	}
+	var config webhooksConfig
+	if err := readSimulationConfig(filepath.Dir(file), filepath.Base(file), &config); os.IsNotExist(err) {
+		return hooks, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, prefix := range config.Allow {
+		if err := checkWebhook(webhook{Url: prefix}); err != nil {
+			return nil, errors.New("Allowed webhook URLs must be absolute http or https URLs: " + prefix)
+		}
+	}
+	hooks.allow = config.Allow
+	for _, hook := range config.Webhooks {
+		if err := checkWebhook(hook); err != nil {
+			return nil, err
+		}
+		hook.Source = "api"
+		hooks.add(hook)
+	}
+	return hooks, nil
+}
+
+func checkWebhook(hook webhook) error {
+	target, err := url.Parse(hook.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || len(target.Host) == 0 {
+		return errors.New("Webhook URL must be an absolute http or https URL: " + hook.Url)
+	}
+	for _, event := range hook.Events {
+		if !webhookEvents[event] {
+			return errors.New("Unknown webhook event " + event)
+		}
+	}
+	return nil
+}
+
+// allowed tells whether a webhook URL is below one of the allowed prefixes, on the same host.
+func (hooks *webhooks) allowed(hookUrl string) bool {
+	target, err := url.Parse(hookUrl)
+	if err != nil || strings.Contains(target.Path, "..") {
+		return false
+	}
+	for _, allowed := range hooks.allow {
+		prefix, err := url.Parse(allowed)
+		if err == nil && target.Scheme == prefix.Scheme && strings.EqualFold(target.Host, prefix.Host) &&
+			withinPath(target.EscapedPath(), prefix.EscapedPath()) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinPath tells whether a URL path is the prefix path or below it, matching whole path segments.
+func withinPath(path string, prefix string) bool {
+	if path == prefix || len(prefix) == 0 {
+		return true
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return strings.HasPrefix(path, prefix)
+}
+
+func (hooks *webhooks) add(hook webhook) webhook {
+	hooks.mutex.Lock()
+	defer hooks.mutex.Unlock()
+	hooks.nextId++
+	hook.Id = hooks.nextId
+	hooks.hooks = append(hooks.hooks, hook)
+	return hook
+}
+
+// remove removes the webhook with the given id, if it has the given source.
+func (hooks *webhooks) remove(id int, source string) bool {
+	hooks.mutex.Lock()
+	defer hooks.mutex.Unlock()
+	for i, hook := range hooks.hooks {
+		if hook.Id == id && hook.Source == source {
+			hooks.hooks = append(hooks.hooks[:i], hooks.hooks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// removeSource removes all webhooks with the given source.
+func (hooks *webhooks) removeSource(source string) {
+	hooks.mutex.Lock()
+	defer hooks.mutex.Unlock()
+	var kept []webhook
+	for _, hook := range hooks.hooks {
+		if hook.Source != source {
+			kept = append(kept, hook)
+		}
+	}
+	hooks.hooks = kept
+}
+
+// save writes the allowed URL prefixes and the webhooks added through the API to the file.
+func (hooks *webhooks) save() error {
+	hooks.mutex.Lock()
+	defer hooks.mutex.Unlock()
+	config := webhooksConfig{Allow: hooks.allow, Webhooks: []webhook{}}
+	for _, hook := range hooks.hooks {
+		if hook.Source == "api" {
+			config.Webhooks = append(config.Webhooks, webhook{Url: hook.Url, Events: hook.Events})
+		}
+	}
+	configJson, _ := json.MarshalIndent(config, "", "  ")
+	return ioutil.WriteFile(hooks.file, configJson, 0644)
+}
+
+func (hooks *webhooks) list() []webhook {
+	hooks.mutex.Lock()
+	defer hooks.mutex.Unlock()
+	return append([]webhook{}, hooks.hooks...)
+}
+
+// loadConfig replaces the webhooks of the previous simulation with the ones of the loaded one.
+func (hooks *webhooks) loadConfig(configDir string) {
+	hooks.removeSource(webhooksConfigFile)
+	var config webhooksConfig
+	if err := readSimulationConfig(configDir, webhooksConfigFile, &config); os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		log.Println("Could not read webhook configuration:", err)
+		return
+	}
+	for _, hook := range config.Webhooks {
+		if err := checkWebhook(hook); err != nil {
+			log.Println("Could not add webhook:", err)
+			continue
+		}
+		if !hooks.allowed(hook.Url) {
+			log.Println("Could not add webhook: URL is not among the allowed ones:", hook.Url)
+			continue
+		}
+		hook.Source = webhooksConfigFile
+		hooks.add(hook)
+	}
+}
+
+func wantsEvent(hook webhook, event string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, e := range hook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func currentWebhookStatus(execution libcosim.ExecutionMetrics) webhookStatus {
+	return webhookStatus{
+		Loaded:                       execution.Loaded,
+		ConfigDir:                    execution.ConfigDir,
+		Status:                       execution.Status,
+		ExecutionState:               execution.State,
+		SimulationTime:               execution.Time,
+		RollingAverageRealTimeFactor: execution.RollingAverageRealTimeFactor,
+		RealTimeFactorTarget:         execution.RealTimeFactorTarget,
+		RunningScenario:              execution.RunningScenario,
+		LastErrorCode:                execution.LastErrorCode,
+		LastErrorMessage:             execution.LastErrorMessage,
+	}
+}
+
+// notify posts an event to the webhooks that want it, each in a goroutine of its own.
+func (hooks *webhooks) notify(event string, data interface{}, current webhookStatus) {
+	body, err := json.Marshal(webhookPayload{Event: event, Timestamp: time.Now(), Data: data, Status: current})
+	if err != nil {
+		log.Println("Could not encode", event, "webhook payload:", err)
+		return
+	}
+	for _, hook := range hooks.list() {
+		if wantsEvent(hook, event) {
+			go hooks.deliver(hook, event, body)
+		}
+	}
+}
+
+func (hooks *webhooks) deliver(hook webhook, event string, body []byte) {
+	wait := webhookFirstRetryWait
+	for attempt := 1; ; attempt++ {
+		response, err := hooks.client.Post(hook.Url, "application/json", bytes.NewReader(body))
+		if err == nil {
+			response.Body.Close()
+			if response.StatusCode/100 == 2 {
+				return
+			}
+			err = errors.New(response.Status)
+		}
+		if attempt == webhookAttempts {
+			log.Println("Could not deliver", event, "to webhook", hook.Url, "after", attempt, "attempts:", err)
+			return
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+func takeSnapshot(execution libcosim.ExecutionMetrics) simulationSnapshot {
+	snapshot := simulationSnapshot{
+		loaded:        execution.Loaded,
+		configDir:     execution.ConfigDir,
+		generation:    execution.LoadGeneration,
+		state:         execution.State,
+		scenario:      execution.RunningScenario,
+		bookmarks:     map[int]bool{},
+		firedTriggers: map[int]bool{},
+	}
+	for _, bookmark := range execution.Bookmarks {
+		snapshot.bookmarks[bookmark.Id] = true
+	}
+	for _, fired := range execution.FiredTriggers {
+		snapshot.firedTriggers[fired.Trend] = true
+	}
+	return snapshot
+}
+
+// watch compares the execution metrics cached by the command loop with the previous check, and
+// notifies the webhooks of the changes.
+func (hooks *webhooks) watch(metrics *libcosim.Metrics) {
+	previous := simulationSnapshot{}
+	for range time.Tick(webhookCheckInterval) {
+		execution := metrics.Execution()
+		current := takeSnapshot(execution)
+		webhookStatus := currentWebhookStatus(execution)
+
+		if current.loaded && (!previous.loaded || current.generation != previous.generation) {
+			if previous.loaded {
+				hooks.notify("unloaded", map[string]string{"configDir": previous.configDir}, webhookStatus)
+			}
+			hooks.loadConfig(current.configDir)
+			hooks.notify("loaded", map[string]string{"configDir": current.configDir}, webhookStatus)
+			previous = current
+			continue
+		}
+		if !current.loaded {
+			if previous.loaded {
+				hooks.notify("unloaded", map[string]string{"configDir": previous.configDir}, webhookStatus)
+				hooks.removeSource(webhooksConfigFile)
+			}
+			previous = current
+			continue
+		}
+
+		if current.state == executionErrorState && previous.state != executionErrorState {
+			hooks.notify("execution-error", map[string]string{
+				"lastErrorCode":    webhookStatus.LastErrorCode,
+				"lastErrorMessage": webhookStatus.LastErrorMessage,
+			}, webhookStatus)
+		}
+		current.abortedScenario = previous.abortedScenario
+		for _, bookmark := range execution.Bookmarks {
+			if previous.bookmarks[bookmark.Id] {
+				continue
+			}
+			if libcosim.IsScenarioAbort(bookmark) {
+				current.abortedScenario = true
+			}
+			hooks.notify("bookmark", bookmark, webhookStatus)
+		}
+		if len(previous.scenario) > 0 && current.scenario != previous.scenario {
+			event := "scenario-finished"
+			if current.abortedScenario {
+				event = "scenario-aborted"
+			}
+			hooks.notify(event, map[string]string{"scenario": previous.scenario}, webhookStatus)
+		}
+		if current.scenario != previous.scenario {
+			current.abortedScenario = false
+		}
+		for _, fired := range execution.FiredTriggers {
+			if !previous.firedTriggers[fired.Trend] {
+				hooks.notify("trigger-fired", map[string]interface{}{
+					"trend":   fired.Trend,
+					"label":   fired.Label,
+					"trigger": fired.Trigger,
+				}, webhookStatus)
+			}
+		}
+		previous = current
+	}
+}
+
+func (hooks *webhooks) listHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hooks.list())
+}
+
+func (hooks *webhooks) addHandler(w http.ResponseWriter, r *http.Request) {
+	var hook webhook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		http.Error(w, "Could not parse webhook: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := checkWebhook(hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !hooks.allowed(hook.Url) {
+		http.Error(w, "Webhook URL is not among the allowed ones: "+hook.Url, http.StatusForbidden)
+		return
+	}
+	hook.Source = "api"
+	hook = hooks.add(hook)
+	if err := hooks.save(); err != nil {
+		hooks.remove(hook.Id, "api")
+		http.Error(w, "Could not save webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+func (hooks *webhooks) removeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		http.Error(w, "Webhook id must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	if !hooks.remove(id, "api") {
+		http.Error(w, "No webhook registered through the API with id "+strconv.Itoa(id), http.StatusNotFound)
+		return
+	}
+	if err := hooks.save(); err != nil {
+		http.Error(w, "Removed the webhook, but could not save the webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
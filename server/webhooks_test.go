@@ -0,0 +1,109 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package server
+
+import (
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWebhookAllowed(t *testing.T) {
+	hooks := &webhooks{allow: []string{"http://hooks.example.com:8080/cosim/", "https://chat.example.com", "http://notify.example.com/hooks"}}
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"http://hooks.example.com:8080/cosim/done", true},
+		{"http://HOOKS.example.com:8080/cosim/done", true},
+		{"https://chat.example.com/notify", true},
+		{"http://hooks.example.com:8080/other", false},
+		{"http://hooks.example.com/cosim/done", false},
+		{"https://hooks.example.com:8080/cosim/done", false},
+		{"http://hooks.example.com:8080/cosim/../admin", false},
+		{"http://hooks.example.com.evil.com:8080/cosim/", false},
+		{"http://chat.example.com/notify", false},
+		{"http://127.0.0.1/", false},
+		{"http://notify.example.com/hooks", true},
+		{"http://notify.example.com/hooks/done", true},
+		{"http://notify.example.com/hooks-evil", false},
+		{"http://hooks.example.com:8080/cosim-evil/done", false},
+	}
+	for _, test := range tests {
+		if got := hooks.allowed(test.url); got != test.allowed {
+			t.Errorf("allowed(%s) = %v, want %v", test.url, got, test.allowed)
+		}
+	}
+}
+
+func serveWebhooks(hooks *webhooks, method string, path string, body string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/webhooks", hooks.addHandler).Methods("POST")
+	router.HandleFunc("/webhooks/{id}", hooks.removeHandler).Methods("DELETE")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return recorder
+}
+
+func TestWebhooksWithoutAllowList(t *testing.T) {
+	hooks, err := newWebhooks("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := serveWebhooks(hooks, "POST", "/webhooks", `{"url": "http://127.0.0.1/hook"}`).Code; got != http.StatusForbidden {
+		t.Errorf("got status %d adding a webhook without an allow-list, want %d", got, http.StatusForbidden)
+	}
+}
+
+func TestWebhooksSaved(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "webhooks.json")
+	if err := ioutil.WriteFile(file, []byte(`{"allow": ["http://hooks.example.com/"], "webhooks": [{"url": "http://other.example.com/hook"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := newWebhooks(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := serveWebhooks(hooks, "POST", "/webhooks", `{"url": "http://other.example.com/hook"}`).Code; got != http.StatusForbidden {
+		t.Errorf("got status %d adding a webhook that isn't allowed, want %d", got, http.StatusForbidden)
+	}
+	if got := serveWebhooks(hooks, "POST", "/webhooks", `{"url": "http://hooks.example.com/done", "events": ["loaded"]}`).Code; got != http.StatusCreated {
+		t.Fatalf("got status %d adding an allowed webhook, want %d", got, http.StatusCreated)
+	}
+	if got := serveWebhooks(hooks, "DELETE", "/webhooks/1", "").Code; got != http.StatusNoContent {
+		t.Fatalf("got status %d removing a webhook, want %d", got, http.StatusNoContent)
+	}
+
+	reread, err := newWebhooks(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []webhook{{Id: 1, Url: "http://hooks.example.com/done", Events: []string{"loaded"}, Source: "api"}}
+	if got := reread.list(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got webhooks %v after reading them back, want %v", got, want)
+	}
+	if !reflect.DeepEqual(reread.allow, []string{"http://hooks.example.com/"}) {
+		t.Errorf("got allowed URLs %v after reading them back, want the ones written", reread.allow)
+	}
+}
+
+func TestWebhooksConfigAllowed(t *testing.T) {
+	configDir := t.TempDir()
+	config := `{"webhooks": [{"url": "http://hooks.example.com/done"}, {"url": "http://127.0.0.1/admin"}]}`
+	if err := ioutil.WriteFile(filepath.Join(configDir, webhooksConfigFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hooks := &webhooks{allow: []string{"http://hooks.example.com/"}}
+	hooks.loadConfig(configDir)
+	want := []webhook{{Id: 1, Url: "http://hooks.example.com/done", Source: webhooksConfigFile}}
+	if got := hooks.list(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got webhooks %v from the configuration folder, want only the allowed %v", got, want)
+	}
+}
@@ -5,6 +5,7 @@
 package server
 
 import (
+	"cosim-demo-app/libcosim"
 	"cosim-demo-app/structs"
 	"github.com/gorilla/websocket"
 	"github.com/ugorji/go/codec"
@@ -12,20 +13,52 @@ import (
 	"log"
 	"net/http"
 	"reflect"
+	"sync/atomic"
 )
 
 type JsonRequest struct {
 	Command []string `json:"command,omitempty"`
 }
 
-var upgrader = websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+// Clients choose the message format with the WebSocket subprotocol or the query parameter
+// format. Msgpack in binary frames is the default, JSON is sent in text frames.
+const (
+	msgpackFormat = "msgpack"
+	jsonFormat    = "json"
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin:  func(r *http.Request) bool { return true },
+	Subprotocols: []string{msgpackFormat, jsonFormat},
+}
 
-func commandLoop(command chan []string, conn *websocket.Conn) {
-	var (
-		mh codec.MsgpackHandle
-	)
+func codecHandle(format string) codec.Handle {
+	if format == jsonFormat {
+		return &codec.JsonHandle{}
+	}
+	var mh codec.MsgpackHandle
 	mh.MapType = reflect.TypeOf(map[string]interface{}(nil))
-	decoder := codec.NewDecoder(nil, &mh)
+	return &mh
+}
+
+func messageType(format string) int {
+	if format == jsonFormat {
+		return websocket.TextMessage
+	}
+	return websocket.BinaryMessage
+}
+
+func negotiateFormat(r *http.Request, conn *websocket.Conn) string {
+	if conn.Subprotocol() == jsonFormat || r.URL.Query().Get("format") == jsonFormat {
+		return jsonFormat
+	}
+	return msgpackFormat
+}
+
+func commandLoop(command chan structs.ClientCommand, clients *libcosim.Clients, clientId int, conn *websocket.Conn, format string) {
+	defer clients.Unregister(clientId)
+	defer atomic.AddInt64(&websocketConnections, -1)
+	decoder := codec.NewDecoder(nil, codecHandle(format))
 
 	for {
 		data := JsonRequest{}
@@ -44,42 +77,54 @@ func commandLoop(command chan []string, conn *websocket.Conn) {
 			err = io.ErrUnexpectedEOF
 		} else if err != nil {
 			log.Println("Could not parse message:", data, ", error was:", err)
-		} else if data.Command != nil {
-			command <- data.Command
+		} else if len(data.Command) > 0 {
+			command <- structs.ClientCommand{ClientId: clientId, Command: data.Command}
 		}
 	}
 }
 
-func stateLoop(state chan structs.JsonResponse, conn *websocket.Conn) {
-	var (
-		mh codec.MsgpackHandle
-	)
-	mh.MapType = reflect.TypeOf(map[string]interface{}(nil))
-	encoder := codec.NewEncoder(nil, &mh)
-	for {
-		latestState := <-state
-
-		w, err := conn.NextWriter(2)
+// stateLoop sends the responses for a client, as they are or as deltas when deltaMode is set.
+func stateLoop(state chan structs.JsonResponse, conn *websocket.Conn, format string, deltaMode bool) {
+	encoder := codec.NewEncoder(nil, codecHandle(format))
+	var delta *deltaEncoder
+	if deltaMode {
+		delta = newDeltaEncoder()
+	}
+	for latestState := range state {
+		w, err := conn.NextWriter(messageType(format))
+		if err != nil {
+			log.Println("write error:", err)
+			break
+		}
 		encoder.Reset(w)
-		err = encoder.Encode(latestState)
+		if delta != nil {
+			err = encoder.Encode(delta.encode(latestState))
+		} else {
+			err = encoder.Encode(latestState)
+		}
 		if err != nil {
 			log.Println("write error:", err)
-			log.Println("latestState:", latestState)
-			state <- latestState
 			break
 		}
+		w.Close()
 	}
+	conn.Close()
 }
 
-func WebsocketHandler(command chan []string, state chan structs.JsonResponse) func(w http.ResponseWriter, r *http.Request) {
+// WebsocketHandler registers every connection as a client with its own view of the simulation.
+// Connecting with the query parameter updates=delta gets delta encoded updates, and with the
+// subprotocol json or the query parameter format=json JSON text frames instead of msgpack.
+func WebsocketHandler(command chan structs.ClientCommand, clients *libcosim.Clients) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Print("upgrade:", err)
 			return
 		}
-		//defer conn.Close()
-		go commandLoop(command, conn)
-		go stateLoop(state, conn)
+		format := negotiateFormat(r, conn)
+		clientId, state := clients.Register()
+		atomic.AddInt64(&websocketConnections, 1)
+		go commandLoop(command, clients, clientId, conn, format)
+		go stateLoop(state, conn, format, r.URL.Query().Get("updates") == "delta")
 	}
 }
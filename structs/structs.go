@@ -45,25 +45,45 @@ type JsonResponse struct {
 	Scenario                     *interface{}          `json:"scenario,omitempty"`
 	RunningScenario              string                `json:"running-scenario"`
 	ManipulatedVariables         []ManipulatedVariable `json:"manipulatedVariables"`
+	DerivedSignals               []DerivedSignal       `json:"derived-signals,omitempty"`
+	PlotConfigReport             *PlotConfigReport     `json:"plot-config-report,omitempty"`
+	Captures                     []Capture             `json:"captures,omitempty"`
+	Capture                      *Capture              `json:"capture,omitempty"`
+	Bookmarks                    []Bookmark            `json:"bookmarks,omitempty"`
+	Reference                    *Reference            `json:"reference"`
+	Run                          *Run                  `json:"run"`
+	WatchList                    []Module              `json:"watch-list,omitempty"`
 }
 
 type TrendSignal struct {
-	Module         string    `json:"module"`
-	SlaveIndex     int       `json:"slave-index"`
-	Signal         string    `json:"signal"`
-	Causality      string    `json:"causality"`
-	Type           string    `json:"type"`
-	ValueReference int       `json:"value-reference"`
-	TrendXValues   []float64 `json:"xvals,omitempty"`
-	TrendYValues   []float64 `json:"yvals,omitempty"`
+	Module           string    `json:"module"`
+	SlaveIndex       int       `json:"slave-index"`
+	Signal           string    `json:"signal"`
+	Causality        string    `json:"causality"`
+	Type             string    `json:"type"`
+	ValueReference   int       `json:"value-reference"`
+	Axis             string    `json:"axis,omitempty"`
+	YAxis            int       `json:"y-axis,omitempty"`
+	Scale            *float64  `json:"scale,omitempty"`
+	Offset           float64   `json:"offset,omitempty"`
+	Color            string    `json:"color,omitempty"`
+	Unit             string    `json:"unit,omitempty"`
+	Reference        bool      `json:"reference,omitempty"`
+	TrendXValues     []float64 `json:"xvals,omitempty"`
+	TrendYValues     []float64 `json:"yvals,omitempty"`
+	ReferenceXValues []float64 `json:"ref-xvals,omitempty"`
+	ReferenceYValues []float64 `json:"ref-yvals,omitempty"`
+	DiffYValues      []float64 `json:"diff-yvals,omitempty"`
 }
 
 type Trend struct {
-	Id           int           `json:"id"`
-	PlotType     string        `json:"plot-type"`
-	Label        string        `json:"label"`
-	TrendSignals []TrendSignal `json:"trend-values"`
-	Spec         TrendSpec     `json:"spec"`
+	Id                   int            `json:"id"`
+	PlotType             string         `json:"plot-type"`
+	Label                string         `json:"label"`
+	TrendSignals         []TrendSignal  `json:"trend-values"`
+	Spec                 TrendSpec      `json:"spec"`
+	Trigger              *Trigger       `json:"trigger,omitempty"`
+	UnsupportedVariables []PlotVariable `json:"-"`
 }
 
 type TrendSpec struct {
@@ -74,22 +94,47 @@ type TrendSpec struct {
 }
 
 type ShortLivedData struct {
-	Scenarios  *[]string
-	Scenario   *interface{}
-	ModuleData *MetaData
+	Scenarios        *[]string
+	Scenario         *interface{}
+	ModuleData       *MetaData
+	PlotConfigReport *PlotConfigReport
+	Capture          *Capture
 }
 
 type SimulationStatus struct {
-	Loading             bool
-	Loaded              bool
-	ConfigDir           string
-	LibVersion          Versions
+	Loading         bool
+	Loaded          bool
+	ConfigDir       string
+	LibVersion      Versions
+	View            ClientView
+	Trends          []Trend
+	NextTrendId     int
+	Status          string
+	CurrentScenario string
+	DerivedSignals  []DerivedSignal
+	Captures        []Capture
+	Bookmarks       []Bookmark
+	LogDir          string
+	Reference       *Reference
+	Run             *Run
+}
+
+type WatchedVariable struct {
+	Module   string   `json:"module"`
+	Variable Variable `json:"variable"`
+}
+
+type ClientView struct {
 	Module              string
 	SignalSubscriptions []Variable
-	Trends              []Trend
-	Status              string
-	CurrentScenario     string
 	ActiveTrend         int
+	UpdateInterval      int
+	WatchList           []WatchedVariable
+}
+
+type ClientCommand struct {
+	ClientId int
+	Command  []string
 }
 
 type Variable struct {
@@ -114,11 +159,19 @@ type CommandFeedback struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 	Command string `json:"command"`
+	Code    int    `json:"code,omitempty"`
+	TrendId int    `json:"trend-id,omitempty"`
 }
 
 type PlotVariable struct {
-	Simulator string `json:"simulator"`
-	Variable  string `json:"variable"`
+	Simulator string   `json:"simulator"`
+	Variable  string   `json:"variable"`
+	Axis      string   `json:"axis,omitempty"`
+	YAxis     int      `json:"yAxis,omitempty"`
+	Scale     *float64 `json:"scale,omitempty"`
+	Offset    float64  `json:"offset,omitempty"`
+	Color     string   `json:"color,omitempty"`
+	Unit      string   `json:"unit,omitempty"`
 }
 
 type Plot struct {
@@ -128,7 +181,75 @@ type Plot struct {
 }
 
 type PlotConfig struct {
-	Plots []Plot `json:"plots"`
+	Plots          []Plot          `json:"plots"`
+	DerivedSignals []DerivedSignal `json:"derivedSignals,omitempty"`
+}
+
+type PlotConfigReport struct {
+	File              string         `json:"file"`
+	MissingSimulators []string       `json:"missingSimulators,omitempty"`
+	MissingVariables  []PlotVariable `json:"missingVariables,omitempty"`
+	UnsupportedTypes  []PlotVariable `json:"unsupportedTypes,omitempty"`
+	Errors            []string       `json:"errors,omitempty"`
+}
+
+type PlotConfigs struct {
+	Names   []string `json:"names"`
+	Default string   `json:"default"`
+}
+
+type DerivedSignal struct {
+	Module     string `json:"module"`
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+type Trigger struct {
+	Module  string  `json:"module"`
+	Signal  string  `json:"signal"`
+	Edge    string  `json:"edge"`
+	Level   float64 `json:"level"`
+	Pre     float64 `json:"pre"`
+	Post    float64 `json:"post"`
+	Armed   bool    `json:"armed"`
+	Fired   bool    `json:"fired"`
+	FiredAt float64 `json:"fired-at"`
+}
+
+type Capture struct {
+	Id           int           `json:"id"`
+	TrendId      int           `json:"trend-id"`
+	Label        string        `json:"label"`
+	Trigger      Trigger       `json:"trigger"`
+	TrendSignals []TrendSignal `json:"trend-values,omitempty"`
+}
+
+type Bookmark struct {
+	Id     int     `json:"id"`
+	Time   float64 `json:"time"`
+	Label  string  `json:"label"`
+	Source string  `json:"source"`
+}
+
+type Reference struct {
+	Path    string   `json:"path"`
+	Signals []string `json:"signals"`
+}
+
+type Run struct {
+	Path      string     `json:"path"`
+	Id        string     `json:"id,omitempty"`
+	Modules   MetaData   `json:"modules"`
+	Bookmarks []Bookmark `json:"bookmarks,omitempty"`
+}
+
+type RunInfo struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Id      string   `json:"id"`
+	Started string   `json:"started"`
+	Files   int      `json:"files"`
+	Modules []string `json:"modules"`
 }
 
 type Versions struct {